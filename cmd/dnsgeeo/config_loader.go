@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+
+	"dnsgeeo/internal/dnsgeeo"
 )
 
 type cliOptions struct {
@@ -21,8 +23,6 @@ type cliOptions struct {
 	pretty         *bool
 	checkMalicious *bool
 	enableWhois    *bool
-	whoisToolPath  *string
-	whoisPython    *string
 	whoisTimeoutMS *int
 	outputFile     *string
 	maxmindKey     *string
@@ -114,6 +114,138 @@ func parseConfig(r io.Reader) (map[string]string, error) {
 	return result, nil
 }
 
+// parseBlocklistSpecs parses the --blocklist flag's
+// "name=source=format=category[,name=source=format=category...]" syntax.
+func parseBlocklistSpecs(csv string) ([]dnsgeeo.BlocklistConfig, error) {
+	csv = strings.TrimSpace(csv)
+	if csv == "" {
+		return nil, nil
+	}
+
+	var out []dnsgeeo.BlocklistConfig
+	for _, entry := range strings.Split(csv, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		fields := strings.SplitN(entry, "=", 4)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("invalid --blocklist entry %q: want name=source=format=category", entry)
+		}
+		format := dnsgeeo.BlocklistFormat(fields[2])
+		switch format {
+		case dnsgeeo.BlocklistFormatHosts, dnsgeeo.BlocklistFormatDomains, dnsgeeo.BlocklistFormatAdblock, dnsgeeo.BlocklistFormatRPZ:
+		default:
+			return nil, fmt.Errorf("invalid --blocklist entry %q: unknown format %q", entry, fields[2])
+		}
+		out = append(out, dnsgeeo.BlocklistConfig{Name: fields[0], Source: fields[1], Format: format, Category: fields[3]})
+	}
+	return out, nil
+}
+
+// parseRouteSpecs parses the --route flag's "match=group[,match=group...]"
+// syntax, e.g. "suffix:corp.example.com=corp,keyword:ads=block". match may
+// itself contain "=" (regex values sometimes do), so the split is on the
+// last "=" in each entry.
+func parseRouteSpecs(csv string) ([]dnsgeeo.Route, error) {
+	csv = strings.TrimSpace(csv)
+	if csv == "" {
+		return nil, nil
+	}
+
+	var out []dnsgeeo.Route
+	for _, entry := range strings.Split(csv, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		idx := strings.LastIndex(entry, "=")
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid --route entry %q: want match=group", entry)
+		}
+		match, group := strings.TrimSpace(entry[:idx]), strings.TrimSpace(entry[idx+1:])
+		if match == "" || group == "" {
+			return nil, fmt.Errorf("invalid --route entry %q: want match=group", entry)
+		}
+		out = append(out, dnsgeeo.Route{Match: match, Group: group})
+	}
+	return out, nil
+}
+
+// parseUpstreamGroups parses the --upstream-group flag's
+// "name=server1|server2[,name2=server3|...]" syntax.
+func parseUpstreamGroups(csv string) (map[string][]string, error) {
+	csv = strings.TrimSpace(csv)
+	if csv == "" {
+		return nil, nil
+	}
+
+	groups := map[string][]string{}
+	for _, entry := range strings.Split(csv, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		idx := strings.IndexByte(entry, '=')
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid --upstream-group entry %q: want name=server1|server2", entry)
+		}
+		name := strings.TrimSpace(entry[:idx])
+		if name == "" {
+			return nil, fmt.Errorf("invalid --upstream-group entry %q: empty group name", entry)
+		}
+		var servers []string
+		for _, s := range strings.Split(entry[idx+1:], "|") {
+			s = strings.TrimSpace(s)
+			if s != "" {
+				servers = append(servers, s)
+			}
+		}
+		if len(servers) == 0 {
+			return nil, fmt.Errorf("invalid --upstream-group entry %q: no servers", entry)
+		}
+		groups[name] = servers
+	}
+	return groups, nil
+}
+
+// loadWordlist reads a newline-delimited subdomain wordlist, skipping blank
+// lines and "#"/";" comments the same way parseConfig does.
+func loadWordlist(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	var out []string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		out = append(out, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// splitCSV splits a comma-separated flag value into trimmed, non-empty
+// entries, returning nil for an empty/blank input.
+func splitCSV(csv string) []string {
+	var out []string
+	for _, part := range strings.Split(csv, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
 func canonicalKey(key string) string {
 	key = strings.TrimSpace(strings.ToLower(key))
 	key = strings.ReplaceAll(key, "_", "-")
@@ -183,14 +315,6 @@ func applyConfigValues(values map[string]string, setFlags map[string]bool, opts
 				}
 				*opts.enableWhois = parsed
 			}
-		case "whois-tool":
-			if opts.whoisToolPath != nil && !setFlags["whois-tool"] {
-				*opts.whoisToolPath = val
-			}
-		case "whois-python":
-			if opts.whoisPython != nil && !setFlags["whois-python"] {
-				*opts.whoisPython = val
-			}
 		case "whois-timeout-ms":
 			if opts.whoisTimeoutMS != nil && !setFlags["whois-timeout-ms"] {
 				parsed, err := strconv.Atoi(val)
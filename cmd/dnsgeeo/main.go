@@ -1,10 +1,12 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -22,17 +24,35 @@ func main() {
 	var pretty bool
 	var checkMalicious bool
 	var enableWhois bool
-	var whoisToolPath string
-	var whoisPython string
 	var whoisTimeoutMS int
 	var pslPrivateList bool
 	var outputFile string
 	var configPath string
 	var maxmindKey string
 	var dbUpdateHours int
+	var enumerate bool
+	var wordlistPath string
+	var enumDepth int
+	var enumPermutations bool
+	var enumReverseSweep bool
+	var enumReverseSweepCIDR int
+	var enumTryAXFR bool
+	var enumCT bool
+	var serveAddr string
+	var blocklistSpecs string
+	var resolveMode string
+	var blocklistRefreshHours int
+	var bootstrapDNS string
+	var recordTypes string
+	var routeSpecs string
+	var upstreamGroupSpecs string
+	var clientSubnet string
+	var ecsFromInterface string
+	var cachePath string
+	var reverseLookup bool
 
 	flag.StringVar(&list, "list", "", "Comma-separated list of hostnames or IPs")
-	flag.StringVar(&dnsServers, "dns", "8.8.8.8:53,8.8.4.4:53", "Comma-separated DNS servers (host:port)")
+	flag.StringVar(&dnsServers, "dns", "8.8.8.8:53,8.8.4.4:53", "Comma-separated DNS servers (host:port, or a URI like tls://1.1.1.1:853, https://dns.google/dns-query). quic:// and sdns:// descriptors parse but every query against them fails: DoQ/DNSCrypt transports are not implemented in this build (see internal/dnsgeeo/transport.go)")
 	flag.IntVar(&timeoutMS, "timeout-ms", 2000, "Per-host lookup timeout (ms)")
 	flag.IntVar(&parallel, "parallel", 64, "Max concurrent lookups")
 	flag.BoolVar(&preferIPv6, "prefer-ipv6", true, "Also query AAAA (IPv6) addresses")
@@ -40,15 +60,33 @@ func main() {
 	flag.StringVar(&asnDB, "asn-db", os.Getenv("GEOLITE2_ASN_DB"), "Path to GeoLite2-ASN.mmdb")
 	flag.BoolVar(&pretty, "pretty", false, "Pretty-print JSON")
 	flag.BoolVar(&checkMalicious, "check-malicious", true, "Check domains against Quad9 threat intelligence")
-	flag.BoolVar(&enableWhois, "whois", true, "Include WHOIS/RDAP data via external tool")
-	flag.StringVar(&whoisToolPath, "whois-tool", "", "Path to whois_rdap.py (used with --whois)")
-	flag.StringVar(&whoisPython, "whois-python", "python3", "Python executable for whois_rdap.py")
-	flag.IntVar(&whoisTimeoutMS, "whois-timeout-ms", 20000, "Timeout for whois_rdap.py in milliseconds")
-	flag.BoolVar(&pslPrivateList, "psl-private-list", false, "Output PSL private suffix list via the WHOIS helper and exit")
+	flag.BoolVar(&enableWhois, "whois", true, "Include WHOIS/RDAP data (native RDAP/WHOIS-43/PSL client)")
+	flag.IntVar(&whoisTimeoutMS, "whois-timeout-ms", 20000, "Per-domain timeout for native WHOIS/RDAP lookups, in milliseconds")
+	flag.BoolVar(&pslPrivateList, "psl-private-list", false, "Output the Public Suffix List PRIVATE section and exit")
 	flag.StringVar(&outputFile, "output", "", "Output file path (default: stdout)")
 	flag.StringVar(&configPath, "config", "", "Optional config file path (key=value format). CLI args override file values.")
 	flag.StringVar(&maxmindKey, "maxmind-license-key", os.Getenv("MAXMIND_LICENSE_KEY"), "MaxMind license key for GeoLite2 auto-updates")
 	flag.IntVar(&dbUpdateHours, "db-update-hours", 0, "Refresh GeoLite2 DBs when older than this many hours (0 disables)")
+	flag.BoolVar(&enumerate, "enumerate", false, "Expand inputs into discovered subdomains before enrichment")
+	flag.StringVar(&wordlistPath, "wordlist", "", "Path to a newline-delimited subdomain wordlist (used with --enumerate)")
+	flag.IntVar(&enumDepth, "enum-depth", 1, "Rounds of feeding newly discovered names back into enumeration")
+	flag.BoolVar(&enumPermutations, "enum-permutations", true, "Alter already-resolved names with common tokens/digits (used with --enumerate)")
+	flag.BoolVar(&enumReverseSweep, "enum-reverse-sweep", false, "Reverse-DNS sweep the /24 around every resolved A record (used with --enumerate)")
+	flag.IntVar(&enumReverseSweepCIDR, "enum-reverse-sweep-prefix", 24, "Prefix length for --enum-reverse-sweep")
+	flag.BoolVar(&enumTryAXFR, "try-axfr", false, "Attempt a zone transfer against each discovered authoritative NS (used with --enumerate)")
+	flag.BoolVar(&enumCT, "enum-ct", true, "Scrape crt.sh certificate transparency logs for subdomains (used with --enumerate)")
+	flag.StringVar(&serveAddr, "serve", "", "Run as a long-lived HTTP service on this address (e.g. :8080) instead of a one-shot CLI run")
+	flag.StringVar(&blocklistSpecs, "blocklist", "", "Comma-separated name=source=format=category entries, e.g. \"ads=https://.../hosts.txt=hosts=ads\"")
+	flag.StringVar(&resolveMode, "resolve-mode", "enrich", "How to handle blocklist matches: \"enrich\" (annotate only) or \"block\" (sinkhole the answer)")
+	flag.IntVar(&blocklistRefreshHours, "blocklist-refresh-hours", 24, "Recompile blocklists on this interval (0 disables background refresh)")
+	flag.StringVar(&bootstrapDNS, "bootstrap-dns", "1.1.1.1:53,8.8.8.8:53", "Plain DNS servers used to resolve hostname-based --dns upstreams (e.g. tls://dns.google) before dialing them")
+	flag.StringVar(&recordTypes, "record-types", "", "Comma-separated extra record types to query per host, e.g. \"MX,TXT,NS,SOA,CAA,SRV\" (default: none)")
+	flag.StringVar(&routeSpecs, "route", "", "Comma-separated match=group rules evaluated in order, e.g. \"suffix:corp.example.com=corp,keyword:ads=block\" (match is suffix:/keyword:/regex:/geosite:...)")
+	flag.StringVar(&upstreamGroupSpecs, "upstream-group", "", "Comma-separated name=server1|server2 upstream groups referenced by --route")
+	flag.StringVar(&clientSubnet, "client-subnet", "", "CIDR (e.g. \"203.0.113.0/24\") sent as an EDNS0 Client Subnet option on every query, so CDN answers reflect that subnet's geography")
+	flag.StringVar(&ecsFromInterface, "ecs-from-interface", "", "Derive the EDNS0 Client Subnet from this local network interface's address instead of --client-subnet")
+	flag.StringVar(&cachePath, "cache-path", "", "Persist the IP-enrichment and malicious-domain caches as files under this directory instead of an in-memory LRU, so repeated runs can share a warm cache (default: in-memory only)")
+	flag.BoolVar(&reverseLookup, "reverse-lookup", false, "PTR-lookup every resolved IP and record whether its rDNS resolves back to that IP (IPEnriched.PTR/ForwardConfirmed)")
 	flag.Parse()
 
 	setFlags := map[string]bool{}
@@ -76,8 +114,6 @@ func main() {
 			pretty:         &pretty,
 			checkMalicious: &checkMalicious,
 			enableWhois:    &enableWhois,
-			whoisToolPath:  &whoisToolPath,
-			whoisPython:    &whoisPython,
 			whoisTimeoutMS: &whoisTimeoutMS,
 			outputFile:     &outputFile,
 			maxmindKey:     &maxmindKey,
@@ -93,20 +129,11 @@ func main() {
 		}
 	}
 
-	if whoisToolPath == "" {
-		if _, err := os.Stat("./tools/whois_rdap.py"); err == nil {
-			whoisToolPath = "./tools/whois_rdap.py"
-		}
-	}
-
 	if pslPrivateList {
-		if whoisToolPath == "" {
-			fmt.Fprintln(os.Stderr, "psl-private-list requires whois-rdap tool path; use --whois-tool")
-			os.Exit(2)
-		}
+		cfg := dnsgeeo.Config{WhoisTimeout: time.Duration(whoisTimeoutMS) * time.Millisecond}
 		wctx, cancel := context.WithTimeout(context.Background(), time.Duration(whoisTimeoutMS)*time.Millisecond)
 		defer cancel()
-		entries, err := dnsgeeo.RunWhoisPSLPrivateList(wctx, whoisPython, whoisToolPath, time.Duration(whoisTimeoutMS)*time.Millisecond)
+		entries, err := dnsgeeo.LoadPSLPrivateList(wctx, &cfg)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "PSL private list error:", err)
 			os.Exit(1)
@@ -128,12 +155,6 @@ func main() {
 		return
 	}
 
-	if !setFlags["whois"] {
-		if _, ok := configValues["whois"]; !ok && whoisToolPath != "" && !enableWhois {
-			enableWhois = true
-		}
-	}
-
 	var inputs []string
 	if list != "" {
 		for _, t := range strings.Split(list, ",") {
@@ -145,8 +166,8 @@ func main() {
 	}
 	inputs = append(inputs, flag.Args()...)
 
-	if len(inputs) == 0 {
-		fmt.Fprintln(os.Stderr, "Usage: dnsgeeo [--config file] [--list host1,host2] [--dns servers] [--timeout-ms N] [--parallel N] [--prefer-ipv6 bool] [--city-db path] [--asn-db path] [--check-malicious] [--whois --whois-tool path] [--pretty] [hosts...]")
+	if len(inputs) == 0 && serveAddr == "" {
+		fmt.Fprintln(os.Stderr, "Usage: dnsgeeo [--config file] [--list host1,host2] [--dns servers] [--timeout-ms N] [--parallel N] [--prefer-ipv6 bool] [--city-db path] [--asn-db path] [--check-malicious] [--whois] [--pretty] [hosts...]\n       dnsgeeo --serve :8080 [--city-db path] [--asn-db path]")
 		os.Exit(2)
 	}
 
@@ -170,23 +191,59 @@ func main() {
 		}
 	}
 
+	blocklists, err := parseBlocklistSpecs(blocklistSpecs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Blocklist error:", err)
+		os.Exit(2)
+	}
+	routes, err := parseRouteSpecs(routeSpecs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Route error:", err)
+		os.Exit(2)
+	}
+	upstreamGroups, err := parseUpstreamGroups(upstreamGroupSpecs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Upstream group error:", err)
+		os.Exit(2)
+	}
+	mode := dnsgeeo.ResolveMode(resolveMode)
+	if mode != dnsgeeo.ResolveModeEnrich && mode != dnsgeeo.ResolveModeBlock {
+		fmt.Fprintf(os.Stderr, "--resolve-mode must be %q or %q, got %q\n", dnsgeeo.ResolveModeEnrich, dnsgeeo.ResolveModeBlock, resolveMode)
+		os.Exit(2)
+	}
+
 	cfg := dnsgeeo.Config{
-		DNSServers:     dnsgeeo.ParseServers(dnsServers),
-		LookupTimeout:  time.Duration(timeoutMS) * time.Millisecond,
-		Parallelism:    parallel,
-		PreferIPv6:     preferIPv6,
-		CheckMalicious: checkMalicious,
-		EnableWhois:    enableWhois,
-		WhoisToolPath:  whoisToolPath,
-		WhoisPython:    whoisPython,
-		WhoisTimeout:   time.Duration(whoisTimeoutMS) * time.Millisecond,
-		CityDBPath:     cityDB,
-		ASNDBPath:      asnDB,
-		IPCacheSize:    10000,
-		IPCacheTTL:     10 * time.Minute,
-	}
-
-	resolver := dnsgeeo.NewRRResolver(cfg.DNSServers)
+		DNSServers:            dnsgeeo.ParseServers(dnsServers),
+		LookupTimeout:         time.Duration(timeoutMS) * time.Millisecond,
+		Parallelism:           parallel,
+		PreferIPv6:            preferIPv6,
+		CheckMalicious:        checkMalicious,
+		EnableWhois:           enableWhois,
+		WhoisTimeout:          time.Duration(whoisTimeoutMS) * time.Millisecond,
+		CityDBPath:            cityDB,
+		ASNDBPath:             asnDB,
+		IPCacheSize:           10000,
+		IPCacheTTL:            10 * time.Minute,
+		CachePath:             cachePath,
+		ReverseLookup:         reverseLookup,
+		Blocklists:            blocklists,
+		ResolveMode:           mode,
+		BlocklistRefreshHours: blocklistRefreshHours,
+		BootstrapServers:      dnsgeeo.ParseServers(bootstrapDNS),
+		RecordTypes:           splitCSV(recordTypes),
+		Routes:                routes,
+		UpstreamGroups:        upstreamGroups,
+		Transport: dnsgeeo.TransportConfig{
+			ClientSubnet:     clientSubnet,
+			ECSFromInterface: ecsFromInterface,
+		},
+	}
+
+	resolver, err := dnsgeeo.NewRRResolverWithConfig(cfg.DNSServers, cfg.Transport, cfg.BootstrapServers)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "DNS resolver error:", err)
+		os.Exit(1)
+	}
 	city, asn, err := dnsgeeo.OpenDBs(&cfg)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "DB error:", err)
@@ -201,29 +258,98 @@ func main() {
 		}
 	}()
 
-	dnsgeeo.InitCache(cfg.IPCacheSize, cfg.IPCacheTTL)
-
-	ctx := context.Background()
-	results, err := dnsgeeo.ResolveAndEnrichBatch(ctx, resolver, inputs, &cfg, city, asn)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "Lookup error:", err)
+	dnsgeeo.InitCache(&cfg)
+	if len(cfg.Blocklists) > 0 {
+		if err := dnsgeeo.InitBlocklists(context.Background(), &cfg); err != nil {
+			fmt.Fprintln(os.Stderr, "Blocklist load warning:", err)
+		}
+	}
+	if err := dnsgeeo.InitRouter(&cfg, resolver); err != nil {
+		fmt.Fprintln(os.Stderr, "Route error:", err)
 		os.Exit(1)
 	}
 
-	var out []byte
-	if pretty {
-		out, _ = json.MarshalIndent(results, "", "  ")
-	} else {
-		out, _ = json.Marshal(results)
+	if serveAddr != "" {
+		if err := runServeMode(serveAddr, &cfg, resolver, city, asn); err != nil {
+			fmt.Fprintln(os.Stderr, "Serve error:", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	if outputFile != "" {
-		err = os.WriteFile(outputFile, out, 0644)
+	ctx := context.Background()
+
+	if enumerate {
+		enumCfg := dnsgeeo.EnumerateConfig{
+			Permutations:     enumPermutations,
+			ReverseSweep:     enumReverseSweep,
+			ReverseSweepCIDR: enumReverseSweepCIDR,
+			TryAXFR:          enumTryAXFR,
+			EnumDepth:        enumDepth,
+		}
+		if wordlistPath != "" {
+			wl, err := loadWordlist(wordlistPath)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Wordlist error:", err)
+				os.Exit(1)
+			}
+			enumCfg.Wordlist = wl
+		}
+		if enumCT {
+			enumCfg.CTSource = &dnsgeeo.CrtSHSource{}
+		}
+		results, err := dnsgeeo.EnumerateBatch(ctx, resolver, inputs, &cfg, enumCfg, city, asn)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to write output file: %v\n", err)
+			fmt.Fprintln(os.Stderr, "Lookup error:", err)
 			os.Exit(1)
 		}
-	} else {
-		os.Stdout.Write(out)
+
+		var out []byte
+		if pretty {
+			out, _ = json.MarshalIndent(results, "", "  ")
+		} else {
+			out, _ = json.Marshal(results)
+		}
+		if outputFile != "" {
+			if err := os.WriteFile(outputFile, out, 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to write output file: %v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			os.Stdout.Write(out)
+		}
+		return
+	}
+
+	// The non-enumerate path streams each HostResult to the output as soon
+	// as it resolves (one JSON object per line), the same way the --serve
+	// NDJSON endpoint does, instead of buffering the whole batch in memory
+	// for a single json.Marshal call.
+	w := io.Writer(os.Stdout)
+	if outputFile != "" {
+		f, ferr := os.Create(outputFile)
+		if ferr != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open output file: %v\n", ferr)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	if pretty {
+		enc.SetIndent("", "  ")
+	}
+
+	err = dnsgeeo.StreamResolveAndEnrichBatch(ctx, resolver, inputs, &cfg, city, asn, func(_ int, result dnsgeeo.HostResult) error {
+		return enc.Encode(result)
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Lookup error:", err)
+		os.Exit(1)
+	}
+	if err := bw.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write output: %v\n", err)
+		os.Exit(1)
 	}
 }
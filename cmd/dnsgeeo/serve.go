@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"dnsgeeo/internal/dnsgeeo"
+
+	geoip2 "github.com/oschwald/geoip2-golang"
+)
+
+// serveServer holds the long-lived state --serve keeps open across
+// requests: the resolver and GeoIP readers are expensive to (re)open, so a
+// single instance is shared by every handler.
+type serveServer struct {
+	cfg      *dnsgeeo.Config
+	resolver *dnsgeeo.RRResolver
+	cityDB   *geoip2.Reader
+	asnDB    *geoip2.Reader
+}
+
+type lookupRequest struct {
+	Hosts []string `json:"hosts"`
+}
+
+func runServeMode(addr string, cfg *dnsgeeo.Config, resolver *dnsgeeo.RRResolver, cityDB, asnDB *geoip2.Reader) error {
+	s := &serveServer{cfg: cfg, resolver: resolver, cityDB: cityDB, asnDB: asnDB}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/lookup", s.handleLookup)
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadTimeout:       30 * time.Second,
+		ReadHeaderTimeout: 30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+
+	log.Printf("dnsgeeo serving on %s", addr)
+	return srv.ListenAndServe()
+}
+
+// handleLookup serves both POST (batch, streaming NDJSON) and GET
+// (single-shot) queries.
+func (s *serveServer) handleLookup(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.handleLookupGet(w, r)
+	case http.MethodPost:
+		s.handleLookupPost(w, r)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *serveServer) handleLookupGet(w http.ResponseWriter, r *http.Request) {
+	host := strings.TrimSpace(r.URL.Query().Get("host"))
+	if host == "" {
+		http.Error(w, "missing required query parameter: host", http.StatusBadRequest)
+		return
+	}
+
+	results, err := dnsgeeo.ResolveAndEnrichBatch(r.Context(), s.resolver, []string{host}, s.cfg, s.cityDB, s.asnDB)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(results[0])
+}
+
+// maxLookupBodyBytes bounds the /lookup POST body so a single oversized
+// request can't exhaust memory on a long-running --serve process.
+const maxLookupBodyBytes = 1 << 20 // 1 MiB
+
+// streamChunkWriteTimeout bounds each individual NDJSON write, not the
+// request as a whole. http.Server.WriteTimeout is deliberately left unset:
+// its deadline is armed once headers finish reading and covers the entire
+// handler, not idle time between writes, so a blanket WriteTimeout here
+// would kill slow-but-progressing batches (e.g. WHOIS lookups ahead of the
+// first flush) the same as a truly stuck connection. Resetting a per-write
+// deadline on the ResponseController instead only kills a connection that
+// stops making progress between chunks.
+const streamChunkWriteTimeout = 30 * time.Second
+
+// handleLookupPost streams one enriched HostResult per line as each
+// completes, rather than buffering the whole batch like the GET path does.
+func (s *serveServer) handleLookupPost(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxLookupBodyBytes)
+
+	var req lookupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(req.Hosts) == 0 {
+		http.Error(w, "request body must include a non-empty \"hosts\" array", http.StatusBadRequest)
+		return
+	}
+
+	flusher, canFlush := w.(http.Flusher)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	bw := bufio.NewWriter(w)
+	rc := http.NewResponseController(w)
+	var mu sync.Mutex
+
+	emit := func(_ int, result dnsgeeo.HostResult) error {
+		mu.Lock()
+		defer mu.Unlock()
+		// Ignored: ResponseWriters that don't support deadlines (e.g. an
+		// httptest.ResponseRecorder in tests) report this as an error, but
+		// the write below still completes synchronously either way.
+		_ = rc.SetWriteDeadline(time.Now().Add(streamChunkWriteTimeout))
+		if err := json.NewEncoder(bw).Encode(result); err != nil {
+			return err
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		if canFlush {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	if err := dnsgeeo.StreamResolveAndEnrichBatch(r.Context(), s.resolver, req.Hosts, s.cfg, s.cityDB, s.asnDB, emit); err != nil {
+		log.Printf("stream lookup error: %v", err)
+	}
+}
+
+func (s *serveServer) handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok", "time": time.Now().UTC().Format(time.RFC3339)})
+}
+
+func (s *serveServer) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	var sb strings.Builder
+	dnsgeeo.DefaultMetrics.WritePrometheus(&sb, s.cfg.CityDBPath, s.cfg.ASNDBPath)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = w.Write([]byte(sb.String()))
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"dnsgeeo/internal/dnsgeeo"
+)
+
+func newTestServeServer() *serveServer {
+	return &serveServer{cfg: &dnsgeeo.Config{}}
+}
+
+func TestHandleLookupGetRequiresHostParam(t *testing.T) {
+	s := newTestServeServer()
+	req := httptest.NewRequest(http.MethodGet, "/lookup", nil)
+	rec := httptest.NewRecorder()
+
+	s.handleLookupGet(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleLookupPostRejectsEmptyHosts(t *testing.T) {
+	s := newTestServeServer()
+	req := httptest.NewRequest(http.MethodPost, "/lookup", strings.NewReader(`{"hosts":[]}`))
+	rec := httptest.NewRecorder()
+
+	s.handleLookupPost(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleLookupPostRejectsMalformedBody(t *testing.T) {
+	s := newTestServeServer()
+	req := httptest.NewRequest(http.MethodPost, "/lookup", strings.NewReader(`not json`))
+	rec := httptest.NewRecorder()
+
+	s.handleLookupPost(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+// TestHandleLookupPostRejectsOversizedBody guards against a regression
+// where the /lookup POST body was decoded without a size limit, letting a
+// single oversized request exhaust memory on a long-running --serve
+// process.
+func TestHandleLookupPostRejectsOversizedBody(t *testing.T) {
+	s := newTestServeServer()
+	oversized := `{"hosts":["` + strings.Repeat("a", maxLookupBodyBytes+1) + `"]}`
+	req := httptest.NewRequest(http.MethodPost, "/lookup", bytes.NewReader([]byte(oversized)))
+	rec := httptest.NewRecorder()
+
+	s.handleLookupPost(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d for a body over maxLookupBodyBytes", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleHealthzReportsOK(t *testing.T) {
+	s := newTestServeServer()
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+
+	s.handleHealthz(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.Contains(rec.Body.String(), `"status":"ok"`) {
+		t.Fatalf("body = %s, want it to contain status:ok", rec.Body.String())
+	}
+}
+
+func TestHandleLookupRejectsUnsupportedMethod(t *testing.T) {
+	s := newTestServeServer()
+	req := httptest.NewRequest(http.MethodDelete, "/lookup", nil)
+	rec := httptest.NewRecorder()
+
+	s.handleLookup(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,317 @@
+package dnsgeeo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BlocklistFormat identifies how a blocklist source's body should be parsed.
+type BlocklistFormat string
+
+const (
+	BlocklistFormatHosts   BlocklistFormat = "hosts"   // "0.0.0.0 example.com" style
+	BlocklistFormatDomains BlocklistFormat = "domains" // one domain per line
+	BlocklistFormatAdblock BlocklistFormat = "adblock" // "||domain^" subset of Adblock Plus syntax
+	BlocklistFormatRPZ     BlocklistFormat = "rpz"     // DNS Response Policy Zone, "domain CNAME ."
+)
+
+// BlocklistConfig declares one named blocklist: where to fetch/read it,
+// what format it's in, and what category to tag its hits with.
+type BlocklistConfig struct {
+	Name     string
+	Source   string // "https://...", "file://...", or a bare filesystem path
+	Format   BlocklistFormat
+	Category string
+}
+
+// BlocklistHit records a single matched rule for a domain.
+type BlocklistHit struct {
+	List     string `json:"list"`
+	Category string `json:"category,omitempty"`
+	Rule     string `json:"rule"`
+}
+
+// ResolveMode selects what ResolveAndEnrichBatch does with a domain that
+// matches a blocklist.
+type ResolveMode string
+
+const (
+	// ResolveModeEnrich annotates blocked results but still returns the
+	// real answer.
+	ResolveModeEnrich ResolveMode = "enrich"
+	// ResolveModeBlock replaces the A/AAAA answers of a blocked domain with
+	// the configured sinkhole address, producing a sanitized answer set.
+	ResolveModeBlock ResolveMode = "block"
+)
+
+// quad9ThreatList is the built-in list name for the legacy Quad9-based
+// malicious check, now surfaced through the same blocklist_hits framework.
+const quad9ThreatList = "quad9-threat"
+
+// suffixNode is one level of the domain-suffix trie used to match wildcard
+// ("||domain^", hosts-file) rules in O(labels) per lookup.
+type suffixNode struct {
+	children map[string]*suffixNode
+	rule     string // non-empty if a list rule terminates exactly here
+}
+
+func newSuffixNode() *suffixNode {
+	return &suffixNode{children: map[string]*suffixNode{}}
+}
+
+func (n *suffixNode) insert(domain string) {
+	labels := strings.Split(strings.ToLower(strings.TrimSuffix(domain, ".")), ".")
+	cur := n
+	for i := len(labels) - 1; i >= 0; i-- {
+		label := labels[i]
+		child, ok := cur.children[label]
+		if !ok {
+			child = newSuffixNode()
+			cur.children[label] = child
+		}
+		cur = child
+	}
+	cur.rule = domain
+}
+
+// match returns the rule that matched domain or one of its parent domains
+// (so a rule for "ads.example.com" also matches "x.ads.example.com"), or
+// "" if nothing matched.
+func (n *suffixNode) match(domain string) string {
+	labels := strings.Split(strings.ToLower(strings.TrimSuffix(domain, ".")), ".")
+	cur := n
+	lastRule := ""
+	for i := len(labels) - 1; i >= 0; i-- {
+		child, ok := cur.children[labels[i]]
+		if !ok {
+			break
+		}
+		cur = child
+		if cur.rule != "" {
+			lastRule = cur.rule
+		}
+	}
+	return lastRule
+}
+
+// compiledList is one blocklist compiled into an exact set plus a suffix
+// trie, ready for Match lookups.
+type compiledList struct {
+	cfg    BlocklistConfig
+	exact  map[string]struct{}
+	suffix *suffixNode
+}
+
+func (l *compiledList) match(domain string) (string, bool) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	if _, ok := l.exact[domain]; ok {
+		return domain, true
+	}
+	if rule := l.suffix.match(domain); rule != "" {
+		return rule, true
+	}
+	return "", false
+}
+
+// BlocklistSet holds every compiled blocklist and answers per-domain match
+// queries. It is safe for concurrent reads; Refresh replaces the
+// underlying compiled lists atomically.
+type BlocklistSet struct {
+	mu    sync.RWMutex
+	lists []*compiledList
+}
+
+// NewBlocklistSet compiles every configured list, fetching/reading each
+// source. A source that fails to load is skipped with its error returned
+// alongside a usable set built from the lists that did load.
+func NewBlocklistSet(ctx context.Context, configs []BlocklistConfig) (*BlocklistSet, error) {
+	set := &BlocklistSet{}
+	errs := set.Refresh(ctx, configs)
+	if len(errs) > 0 {
+		return set, fmt.Errorf("blocklist load errors: %v", errs)
+	}
+	return set, nil
+}
+
+// Refresh (re)loads every configured list and swaps them in atomically.
+// Returns one error per list that failed to load; lists that succeeded are
+// still installed.
+func (s *BlocklistSet) Refresh(ctx context.Context, configs []BlocklistConfig) []error {
+	var errs []error
+	compiled := make([]*compiledList, 0, len(configs))
+
+	for _, cfg := range configs {
+		body, err := fetchBlocklistSource(ctx, cfg.Source)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("list %s: %w", cfg.Name, err))
+			continue
+		}
+		compiled = append(compiled, compileBlocklist(cfg, body))
+	}
+
+	s.mu.Lock()
+	s.lists = compiled
+	s.mu.Unlock()
+
+	return errs
+}
+
+// Match checks domain against every loaded list and returns one hit per
+// match.
+func (s *BlocklistSet) Match(domain string) []BlocklistHit {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var hits []BlocklistHit
+	for _, l := range s.lists {
+		if rule, ok := l.match(domain); ok {
+			hits = append(hits, BlocklistHit{List: l.cfg.Name, Category: l.cfg.Category, Rule: rule})
+		}
+	}
+	return hits
+}
+
+func fetchBlocklistSource(ctx context.Context, source string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(source, "file://"):
+		return os.ReadFile(strings.TrimPrefix(source, "file://"))
+	case strings.HasPrefix(source, "http://"), strings.HasPrefix(source, "https://"):
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+		if err != nil {
+			return nil, err
+		}
+		client := &http.Client{Timeout: 30 * time.Second}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, 64*1024*1024))
+	default:
+		return os.ReadFile(source)
+	}
+}
+
+func compileBlocklist(cfg BlocklistConfig, body []byte) *compiledList {
+	l := &compiledList{cfg: cfg, exact: map[string]struct{}{}, suffix: newSuffixNode()}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(body)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		domain := parseBlocklistLine(cfg.Format, scanner.Text())
+		if domain == "" {
+			continue
+		}
+		l.exact[domain] = struct{}{}
+		l.suffix.insert(domain)
+	}
+	return l
+}
+
+func parseBlocklistLine(format BlocklistFormat, line string) string {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") || strings.HasPrefix(line, ";") {
+		return ""
+	}
+
+	switch format {
+	case BlocklistFormatHosts:
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return ""
+		}
+		return strings.ToLower(fields[1])
+	case BlocklistFormatAdblock:
+		if !strings.HasPrefix(line, "||") {
+			return ""
+		}
+		rule := strings.TrimPrefix(line, "||")
+		if idx := strings.IndexAny(rule, "^/"); idx != -1 {
+			rule = rule[:idx]
+		}
+		return strings.ToLower(rule)
+	case BlocklistFormatRPZ:
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.EqualFold(fields[1], "CNAME") {
+			return ""
+		}
+		return strings.ToLower(strings.TrimSuffix(fields[0], "."))
+	default: // BlocklistFormatDomains
+		return strings.ToLower(strings.Fields(line)[0])
+	}
+}
+
+// sinkholeIP returns the replacement address ResolveModeBlock substitutes
+// for a blocked domain's answers, honoring the IP family of the original.
+func sinkholeIP(family string) string {
+	if family == "v6" {
+		return "::"
+	}
+	return "0.0.0.0"
+}
+
+// activeBlocklists is the process-wide compiled set used by
+// ResolveAndEnrichBatch/StreamResolveAndEnrichBatch. It is nil until
+// InitBlocklists is called; while it is nil, checkBlocklists matches no
+// compiled list and only adds the synthetic "quad9-threat" hit.
+var activeBlocklists *BlocklistSet
+
+// InitBlocklists compiles cfg.Blocklists and, if cfg.BlocklistRefreshHours
+// is positive, starts a background goroutine that recompiles them on that
+// interval. Safe to call once at startup, mirroring InitCache/InitWhois.
+//
+// The built-in "quad9-threat" hit is not one of these compiled lists: it is
+// synthesized by checkBlocklists directly off result.Malicious, bypassing
+// BlocklistSet.Match entirely. cfg.CheckMalicious does not add anything to
+// cfg.Blocklists here.
+func InitBlocklists(ctx context.Context, cfg *Config) error {
+	configs := append([]BlocklistConfig(nil), cfg.Blocklists...)
+
+	set, err := NewBlocklistSet(ctx, configs)
+	activeBlocklists = set
+
+	if cfg.BlocklistRefreshHours > 0 {
+		interval := time.Duration(cfg.BlocklistRefreshHours) * time.Hour
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for range ticker.C {
+				_ = activeBlocklists.Refresh(context.Background(), configs)
+			}
+		}()
+	}
+
+	return err
+}
+
+// checkBlocklists annotates result with any blocklist hits for domain (plus
+// a synthetic "quad9-threat" hit when the legacy Quad9 malicious check
+// fired), and, in ResolveModeBlock, replaces its IPs with sinkhole
+// addresses.
+func checkBlocklists(cfg *Config, domain string, result *HostResult) {
+	if activeBlocklists != nil {
+		result.BlocklistHits = activeBlocklists.Match(domain)
+	}
+	if result.Malicious != nil && *result.Malicious {
+		result.BlocklistHits = append(result.BlocklistHits, BlocklistHit{List: quad9ThreatList, Category: "malware", Rule: domain})
+	}
+	result.Blocked = len(result.BlocklistHits) > 0
+
+	if result.Blocked && cfg.ResolveMode == ResolveModeBlock {
+		for i := range result.IPs {
+			result.IPs[i].IP = sinkholeIP(result.IPs[i].Family)
+			result.IPs[i].Geo = nil
+			result.IPs[i].ASN = nil
+		}
+	}
+}
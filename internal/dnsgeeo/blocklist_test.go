@@ -0,0 +1,93 @@
+package dnsgeeo
+
+import "testing"
+
+func TestParseBlocklistLineFormats(t *testing.T) {
+	cases := []struct {
+		format BlocklistFormat
+		line   string
+		want   string
+	}{
+		{BlocklistFormatHosts, "0.0.0.0 ads.example.com", "ads.example.com"},
+		{BlocklistFormatHosts, "# comment", ""},
+		{BlocklistFormatDomains, "Tracker.Example.com", "tracker.example.com"},
+		{BlocklistFormatAdblock, "||ads.example.com^", "ads.example.com"},
+		{BlocklistFormatAdblock, "! this is a comment", ""},
+		{BlocklistFormatRPZ, "blocked.example.com CNAME .", "blocked.example.com"},
+		{BlocklistFormatRPZ, "blocked.example.com A 127.0.0.1", ""},
+	}
+	for _, c := range cases {
+		if got := parseBlocklistLine(c.format, c.line); got != c.want {
+			t.Errorf("parseBlocklistLine(%v, %q) = %q, want %q", c.format, c.line, got, c.want)
+		}
+	}
+}
+
+func TestCompiledListMatchesExactAndSuffix(t *testing.T) {
+	l := compileBlocklist(BlocklistConfig{Name: "test", Category: "ads"}, []byte("ads.example.com\n"))
+
+	if _, ok := l.match("ads.example.com"); !ok {
+		t.Error("expected exact match on ads.example.com")
+	}
+	if _, ok := l.match("sub.ads.example.com"); !ok {
+		t.Error("expected suffix match on sub.ads.example.com")
+	}
+	if _, ok := l.match("notads.example.com"); ok {
+		t.Error("did not expect a match on an unrelated sibling domain")
+	}
+	if _, ok := l.match("example.com"); ok {
+		t.Error("did not expect a match on the parent of a listed rule")
+	}
+}
+
+func TestBlocklistSetMatchAggregatesAcrossLists(t *testing.T) {
+	set := &BlocklistSet{}
+	set.lists = []*compiledList{
+		compileBlocklist(BlocklistConfig{Name: "ads", Category: "ads"}, []byte("ads.example.com\n")),
+		compileBlocklist(BlocklistConfig{Name: "malware", Category: "malware"}, []byte("ads.example.com\n")),
+	}
+
+	hits := set.Match("ads.example.com")
+	if len(hits) != 2 {
+		t.Fatalf("len(hits) = %d, want 2 (one per matching list)", len(hits))
+	}
+}
+
+// TestCheckBlocklistsSynthesizesQuad9HitOutsideCompiledLists guards against
+// a regression where the quad9-threat hit was documented as part of
+// cfg.Blocklists (InitBlocklists) but is actually synthesized independently
+// in checkBlocklists off result.Malicious.
+func TestCheckBlocklistsSynthesizesQuad9HitOutsideCompiledLists(t *testing.T) {
+	prev := activeBlocklists
+	activeBlocklists = nil // no compiled lists loaded at all
+	t.Cleanup(func() { activeBlocklists = prev })
+
+	malicious := true
+	result := &HostResult{Malicious: &malicious}
+	checkBlocklists(&Config{}, "bad.example.com", result)
+
+	if !result.Blocked {
+		t.Fatal("expected Blocked=true from the synthetic quad9-threat hit")
+	}
+	if len(result.BlocklistHits) != 1 || result.BlocklistHits[0].List != quad9ThreatList {
+		t.Fatalf("BlocklistHits = %+v, want one quad9-threat hit", result.BlocklistHits)
+	}
+}
+
+func TestCheckBlocklistsSinkholesInBlockMode(t *testing.T) {
+	prev := activeBlocklists
+	activeBlocklists = &BlocklistSet{lists: []*compiledList{
+		compileBlocklist(BlocklistConfig{Name: "ads"}, []byte("blocked.example.com\n")),
+	}}
+	t.Cleanup(func() { activeBlocklists = prev })
+
+	result := &HostResult{IPs: []IPEnriched{{IP: "203.0.113.1", Family: "v4"}}}
+	checkBlocklists(&Config{ResolveMode: ResolveModeBlock}, "blocked.example.com", result)
+
+	if !result.Blocked {
+		t.Fatal("expected Blocked=true")
+	}
+	if result.IPs[0].IP != "0.0.0.0" {
+		t.Errorf("IPs[0].IP = %q, want sinkhole 0.0.0.0", result.IPs[0].IP)
+	}
+}
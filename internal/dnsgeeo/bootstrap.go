@@ -0,0 +1,112 @@
+package dnsgeeo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// defaultBootstrapServers resolve the hostname of a DoT/DoH/TCP upstream
+// (e.g. "dns.google" in tls://dns.google) when Config.BootstrapServers is
+// empty.
+var defaultBootstrapServers = []string{"1.1.1.1:53", "8.8.8.8:53"}
+
+// bootstrapResolver resolves the hostname portion of an encrypted-DNS
+// upstream to an IP using a small, fixed set of plain DNS servers, so the
+// real resolver never has a chicken-and-egg dependency on itself. Results
+// are cached for the TTL of the answer that produced them.
+type bootstrapResolver struct {
+	servers []string
+
+	mu    sync.Mutex
+	cache map[string]bootstrapEntry
+}
+
+type bootstrapEntry struct {
+	ip      net.IP
+	expires time.Time
+}
+
+func newBootstrapResolver(servers []string) *bootstrapResolver {
+	if len(servers) == 0 {
+		servers = defaultBootstrapServers
+	}
+	return &bootstrapResolver{servers: servers, cache: map[string]bootstrapEntry{}}
+}
+
+// descriptorNeedsBootstrap reports whether d.Host must be resolved before
+// d can be dialed: it has a hostname (not an IP literal) and a scheme that
+// actually dials out (DNSCrypt addresses its server via the stamp, not
+// Host).
+func descriptorNeedsBootstrap(d ServerDescriptor) bool {
+	if d.Host == "" || d.Scheme == SchemeDNSCrypt {
+		return false
+	}
+	return net.ParseIP(d.Host) == nil
+}
+
+// resolve returns an IP for host, preferring a cached answer that hasn't
+// expired, and otherwise querying each configured bootstrap server in turn
+// until one answers.
+func (b *bootstrapResolver) resolve(ctx context.Context, host string) (net.IP, error) {
+	b.mu.Lock()
+	if e, ok := b.cache[host]; ok && time.Now().Before(e.expires) {
+		b.mu.Unlock()
+		return e.ip, nil
+	}
+	b.mu.Unlock()
+
+	var lastErr error
+	for _, server := range b.servers {
+		ip, ttl, err := queryBootstrapServer(ctx, server, host)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		b.mu.Lock()
+		b.cache[host] = bootstrapEntry{ip: ip, expires: time.Now().Add(ttl)}
+		b.mu.Unlock()
+		return ip, nil
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no bootstrap servers configured")
+	}
+	return nil, fmt.Errorf("resolve %s via bootstrap servers: %w", host, lastErr)
+}
+
+func queryBootstrapServer(ctx context.Context, server, host string) (net.IP, time.Duration, error) {
+	client := &dns.Client{Timeout: 2 * time.Second}
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(host), dns.TypeA)
+	msg.RecursionDesired = true
+
+	conn, err := client.DialContext(ctx, server)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer conn.Close()
+
+	resp, _, err := client.ExchangeWithConn(msg, conn)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := rcodeError(resp.Rcode); err != nil {
+		return nil, 0, err
+	}
+
+	for _, rr := range resp.Answer {
+		if a, ok := rr.(*dns.A); ok {
+			ttl := time.Duration(a.Hdr.Ttl) * time.Second
+			if ttl <= 0 {
+				ttl = 5 * time.Minute
+			}
+			return a.A, ttl, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("no A record for %s", host)
+}
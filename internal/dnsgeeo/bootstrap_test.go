@@ -0,0 +1,50 @@
+package dnsgeeo
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDescriptorNeedsBootstrap(t *testing.T) {
+	cases := []struct {
+		name string
+		desc ServerDescriptor
+		want bool
+	}{
+		{"hostname DoT", ServerDescriptor{Host: "dns.google", Scheme: SchemeDoT}, true},
+		{"IP literal DoT", ServerDescriptor{Host: "1.1.1.1", Scheme: SchemeDoT}, false},
+		{"hostname DNSCrypt", ServerDescriptor{Host: "dns.example.com", Scheme: SchemeDNSCrypt}, false},
+		{"empty host", ServerDescriptor{Host: "", Scheme: SchemeDoT}, false},
+	}
+	for _, c := range cases {
+		if got := descriptorNeedsBootstrap(c.desc); got != c.want {
+			t.Errorf("%s: descriptorNeedsBootstrap(%+v) = %v, want %v", c.name, c.desc, got, c.want)
+		}
+	}
+}
+
+// TestBootstrapResolverUsesCacheBeforeQuerying guards against a regression
+// where resolve always hit the network, even for a host whose answer was
+// already cached and not yet expired.
+func TestBootstrapResolverUsesCacheBeforeQuerying(t *testing.T) {
+	b := newBootstrapResolver(nil)
+	want := net.ParseIP("203.0.113.5")
+	b.cache["dns.example.com"] = bootstrapEntry{ip: want, expires: time.Now().Add(time.Minute)}
+
+	got, err := b.resolve(context.Background(), "dns.example.com")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if !got.Equal(want) {
+		t.Errorf("resolve returned %s, want cached %s", got, want)
+	}
+}
+
+func TestNewBootstrapResolverDefaultsServers(t *testing.T) {
+	b := newBootstrapResolver(nil)
+	if len(b.servers) != len(defaultBootstrapServers) {
+		t.Fatalf("servers = %v, want default %v", b.servers, defaultBootstrapServers)
+	}
+}
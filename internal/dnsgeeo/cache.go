@@ -0,0 +1,117 @@
+package dnsgeeo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+// EnrichmentCache is the pluggable backend behind InitCache. Entries are
+// namespaced by kind ("enrich" for IPEnriched, "mal" for malicious-domain
+// booleans) so the two never collide under the same key. Get reports
+// whether value was found and has not yet expired; Set stores value with
+// a TTL honored on the next Get.
+type EnrichmentCache interface {
+	Get(kind, key string) ([]byte, bool)
+	Set(kind, key string, value []byte, ttl time.Duration)
+}
+
+// memEnrichmentCache is the default backend: an in-process LRU, lost on
+// restart, matching dnsgeeo's behavior before CachePath existed. Its TTL
+// is fixed at construction (expirable.LRU applies one TTL to the whole
+// cache), so the ttl argument to Set is ignored.
+type memEnrichmentCache struct {
+	lru *expirable.LRU[string, []byte]
+}
+
+func newMemEnrichmentCache(size int, ttl time.Duration) *memEnrichmentCache {
+	return &memEnrichmentCache{lru: expirable.NewLRU[string, []byte](size, nil, ttl)}
+}
+
+func (c *memEnrichmentCache) Get(kind, key string) ([]byte, bool) {
+	return c.lru.Get(kind + ":" + key)
+}
+
+func (c *memEnrichmentCache) Set(kind, key string, value []byte, _ time.Duration) {
+	c.lru.Add(kind+":"+key, value)
+}
+
+// fileEnrichmentCache persists entries as one JSON file per key under a
+// directory, so they survive across process restarts and can be shared by
+// parallel invocations pointed at the same CachePath.
+//
+// This is a substitution, not what was asked for: the request wanted an
+// embedded KV store (BoltDB/Badger, both pure Go), which gets compaction
+// and transactional writes for free. Neither is vendored in this build
+// (see NewTransport in transport.go for the same tradeoff on DoQ/DNSCrypt),
+// so this is a stdlib-only stand-in doing one os.Open/os.Stat per lookup
+// instead. It satisfies the same EnrichmentCache interface, so swapping in
+// a real embedded store later is a one-file change, but treat "persistent
+// cache backend" as partially delivered until that swap happens.
+//
+// TODO(wmetcalf/DNS-GEE-O#chunk1-6): swap this for a BoltDB or Badger backed
+// EnrichmentCache. Open follow-up work, not done.
+type fileEnrichmentCache struct {
+	dir string
+	mu  sync.Mutex
+}
+
+type fileCacheEntry struct {
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+func newFileEnrichmentCache(dir string) (*fileEnrichmentCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &fileEnrichmentCache{dir: dir}, nil
+}
+
+func (c *fileEnrichmentCache) entryPath(kind, key string) string {
+	return filepath.Join(c.dir, kind+"_"+sanitizeCacheKey(key)+".json")
+}
+
+func (c *fileEnrichmentCache) Get(kind, key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	raw, err := os.ReadFile(c.entryPath(kind, key))
+	if err != nil {
+		return nil, false
+	}
+	var entry fileCacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, false
+	}
+	if time.Now().After(entry.ExpiresAt) {
+		return nil, false
+	}
+	return []byte(entry.Value), true
+}
+
+func (c *fileEnrichmentCache) Set(kind, key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
+	}
+	raw, err := json.Marshal(fileCacheEntry{Value: value, ExpiresAt: time.Now().Add(ttl)})
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = os.WriteFile(c.entryPath(kind, key), raw, 0o600)
+}
+
+// sanitizeCacheKey replaces "/", "\\" and ":" in key with "_" so it can be
+// used as part of a file name (cache keys are IPs and domain names; IPv6
+// addresses contain ":").
+func sanitizeCacheKey(key string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(key)
+}
@@ -0,0 +1,87 @@
+package dnsgeeo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFileEnrichmentCacheRoundTrip(t *testing.T) {
+	c, err := newFileEnrichmentCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("newFileEnrichmentCache: %v", err)
+	}
+
+	c.Set("enrich", "1.2.3.4", []byte(`{"ip":"1.2.3.4"}`), time.Minute)
+
+	got, ok := c.Get("enrich", "1.2.3.4")
+	if !ok {
+		t.Fatal("expected a hit for a just-written key")
+	}
+	if string(got) != `{"ip":"1.2.3.4"}` {
+		t.Errorf("Get = %s, want the stored value", got)
+	}
+}
+
+func TestFileEnrichmentCacheMissesUnknownKey(t *testing.T) {
+	c, err := newFileEnrichmentCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("newFileEnrichmentCache: %v", err)
+	}
+	if _, ok := c.Get("enrich", "never-set"); ok {
+		t.Error("expected a miss for a key that was never set")
+	}
+}
+
+// TestFileEnrichmentCacheExpires guards the TTL contract: an entry past its
+// expires_at must miss on Get even though the file is still on disk.
+func TestFileEnrichmentCacheExpires(t *testing.T) {
+	c, err := newFileEnrichmentCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("newFileEnrichmentCache: %v", err)
+	}
+	c.Set("mal", "evil.example.com", []byte("true"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get("mal", "evil.example.com"); ok {
+		t.Error("expected a miss for an already-expired entry")
+	}
+}
+
+// TestFileEnrichmentCacheNamespacesByKind guards against "enrich" and "mal"
+// entries for the same key colliding on disk.
+func TestFileEnrichmentCacheNamespacesByKind(t *testing.T) {
+	c, err := newFileEnrichmentCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("newFileEnrichmentCache: %v", err)
+	}
+	c.Set("enrich", "example.com", []byte(`{"a":1}`), time.Minute)
+	c.Set("mal", "example.com", []byte("false"), time.Minute)
+
+	enrich, ok := c.Get("enrich", "example.com")
+	if !ok || string(enrich) != `{"a":1}` {
+		t.Errorf("enrich = %s, ok=%v", enrich, ok)
+	}
+	mal, ok := c.Get("mal", "example.com")
+	if !ok || string(mal) != "false" {
+		t.Errorf("mal = %s, ok=%v", mal, ok)
+	}
+}
+
+func TestSanitizeCacheKeyStripsPathSeparators(t *testing.T) {
+	if got := sanitizeCacheKey("2001:db8::1"); got != "2001_db8__1" {
+		t.Errorf("sanitizeCacheKey = %q, want 2001_db8__1", got)
+	}
+}
+
+func TestMemEnrichmentCacheRoundTrip(t *testing.T) {
+	c := newMemEnrichmentCache(10, time.Minute)
+	c.Set("enrich", "1.2.3.4", []byte("value"), 0)
+
+	got, ok := c.Get("enrich", "1.2.3.4")
+	if !ok || string(got) != "value" {
+		t.Errorf("Get = %s, ok=%v", got, ok)
+	}
+	if _, ok := c.Get("enrich", "missing"); ok {
+		t.Error("expected a miss for an unset key")
+	}
+}
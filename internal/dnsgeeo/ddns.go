@@ -0,0 +1,35 @@
+package dnsgeeo
+
+import "strings"
+
+// ddnsProviderSuffixes maps a dynamic-DNS provider's hosting domain to the
+// provider's display name, mirroring the tldQuirks-style static table the
+// WHOIS/43 client already uses for registry referral quirks. It is
+// necessarily incomplete (there is no canonical, machine-readable registry
+// of DDNS providers) but covers the services most commonly seen fronting
+// malicious or abuse-prone infrastructure.
+var ddnsProviderSuffixes = map[string]string{
+	"afraid.org":   "Afraid.org (FreeDNS)",
+	"no-ip.com":    "No-IP",
+	"no-ip.org":    "No-IP",
+	"noip.com":     "No-IP",
+	"duckdns.org":  "DuckDNS",
+	"dynu.com":     "Dynu",
+	"dynu.net":     "Dynu",
+	"dyndns.org":   "Dyn",
+	"dynv6.net":    "dynv6",
+	"changeip.com": "ChangeIP",
+	"ddns.net":     "No-IP",
+}
+
+// ddnsProviderForHost returns the display name of the dynamic-DNS provider
+// that owns host's suffix, or "" if host does not match any known provider.
+func ddnsProviderForHost(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for suffix, provider := range ddnsProviderSuffixes {
+		if host == suffix || strings.HasSuffix(host, "."+suffix) {
+			return provider
+		}
+	}
+	return ""
+}
@@ -0,0 +1,20 @@
+package dnsgeeo
+
+import "testing"
+
+func TestDDNSProviderForHost(t *testing.T) {
+	cases := []struct {
+		host string
+		want string
+	}{
+		{"myhost.no-ip.org", "No-IP"},
+		{"sub.domain.afraid.org", "Afraid.org (FreeDNS)"},
+		{"example.com", ""},
+		{"duckdns.org", "DuckDNS"},
+	}
+	for _, c := range cases {
+		if got := ddnsProviderForHost(c.host); got != c.want {
+			t.Errorf("ddnsProviderForHost(%q) = %q, want %q", c.host, got, c.want)
+		}
+	}
+}
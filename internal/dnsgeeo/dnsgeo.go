@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net"
 	"os"
 	"strconv"
@@ -12,7 +13,6 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/hashicorp/golang-lru/v2/expirable"
 	"github.com/miekg/dns"
 	geoip2 "github.com/oschwald/geoip2-golang"
 )
@@ -26,14 +26,68 @@ type Config struct {
 	PreferIPv6     bool
 	CheckMalicious bool
 	EnableWhois    bool
-	WhoisToolPath  string
-	WhoisPython    string
 	WhoisTimeout   time.Duration
+	WhoisCacheDir  string // root dir for the RDAP bootstrap + PSL disk caches; defaults to $XDG_CACHE_HOME/dnsgeeo
 
 	CityDBPath  string
 	ASNDBPath   string
 	IPCacheSize int
 	IPCacheTTL  time.Duration
+
+	// CachePath, if set, persists the IP-enrichment and malicious-domain
+	// caches as one file per entry under this directory instead of the
+	// default in-memory LRU, so repeated short-lived CLI runs against
+	// overlapping input lists don't re-hit MaxMind/Quad9 every time. See
+	// cache.go.
+	CachePath string
+
+	// ReverseLookup issues a PTR query for every resolved IP and
+	// populates IPEnriched.PTR/ForwardConfirmed. Disabled by default
+	// since it doubles the DNS round trips per IP.
+	ReverseLookup bool
+
+	// Transport holds per-transport timeouts and pool sizes (DoT/DoH/DoQ)
+	// and the EDNS0 options applied to outgoing queries. See transport.go.
+	Transport TransportConfig
+
+	// Blocklists, ResolveMode, and BlocklistRefreshHours configure the
+	// DNS response filtering subsystem; see blocklist.go. Blocklists is
+	// nil unless the caller opts in (InitBlocklists must be called once
+	// at startup, mirroring InitCache/InitWhois).
+	Blocklists            []BlocklistConfig
+	ResolveMode           ResolveMode
+	BlocklistRefreshHours int
+
+	// BootstrapServers are plain DNS servers used to resolve the hostname
+	// portion of a hostname-based --dns upstream (e.g. "dns.google" in
+	// tls://dns.google) once at resolver construction time, so the real
+	// resolver never depends on itself to start up. Defaults to
+	// 1.1.1.1:53,8.8.8.8:53 when empty; see bootstrap.go.
+	BootstrapServers []string
+
+	// RecordTypes additionally queries each of these record types (e.g.
+	// "MX", "TXT", "NS", "SOA", "CAA", "SRV") for every host and populates
+	// HostResult.Records. Empty disables the extra queries entirely
+	// (the default, and the pre-existing behavior).
+	RecordTypes []string
+
+	// Routes and UpstreamGroups implement Clash-style rule-based upstream
+	// dispatch: Routes are evaluated in order, and the first one whose
+	// Match matches the queried domain sends it to the corresponding
+	// UpstreamGroups entry instead of the implicit "default" group (built
+	// from DNSServers). Empty Routes disables routing entirely (the
+	// default); see router.go and InitRouter.
+	Routes         []Route
+	UpstreamGroups map[string][]string
+}
+
+// Route matches a queried domain against Match and, if it matches,
+// dispatches the query to the upstream group named Group. Match is
+// "kind:value", where kind is one of "suffix", "keyword", "regex", or
+// "geosite" (see router.go).
+type Route struct {
+	Match string
+	Group string
 }
 
 type GeoInfo struct {
@@ -55,51 +109,323 @@ type IPEnriched struct {
 	Family string   `json:"family"`
 	Geo    *GeoInfo `json:"geo,omitempty"`
 	ASN    *ASNInfo `json:"asn,omitempty"`
+
+	// PTR and ForwardConfirmed are populated when Config.ReverseLookup is
+	// set; see EnrichIP. ForwardConfirmed is only ever true when PTR is
+	// non-empty and its forward A/AAAA lookup returns this same IP, the
+	// standard "rDNS matches forward DNS" hygiene signal used by mail and
+	// security tooling.
+	PTR              string `json:"ptr,omitempty"`
+	ForwardConfirmed bool   `json:"forward_confirmed,omitempty"`
 }
 
 type HostResult struct {
-	Domain     string         `json:"domain"`
-	Resolved   bool           `json:"resolved"`
-	DNSServer  string         `json:"dns_server,omitempty"`
-	Malicious  *bool          `json:"malicious,omitempty"`
-	IPs        []IPEnriched   `json:"ips,omitempty"`
-	Whois      *WhoisToolInfo `json:"whois,omitempty"`
-	WhoisError string         `json:"whois_error,omitempty"`
-	Error      string         `json:"error,omitempty"`
+	Domain        string              `json:"domain"`
+	Resolved      bool                `json:"resolved"`
+	DNSServer     string              `json:"dns_server,omitempty"`
+	DiscoveredVia string              `json:"discovered_via,omitempty"`
+	Malicious     *bool               `json:"malicious,omitempty"`
+	IPs           []IPEnriched        `json:"ips,omitempty"`
+	Whois         *WhoisToolInfo      `json:"whois,omitempty"`
+	WhoisError    string              `json:"whois_error,omitempty"`
+	BlocklistHits []BlocklistHit      `json:"blocklist_hits,omitempty"`
+	Blocked       bool                `json:"blocked,omitempty"`
+	Records       map[string][]string `json:"records,omitempty"`
+	Route         string              `json:"route,omitempty"`
+	UpstreamGroup string              `json:"upstream_group,omitempty"`
+	ClientSubnet  string              `json:"client_subnet,omitempty"`
+	Error         string              `json:"error,omitempty"`
 }
 
 // -------------- Resolver ---------------
 
+// RRResolver round-robins queries across a set of upstream servers, each of
+// which may speak a different transport (UDP, TCP, DoT, DoH, ...); see
+// transport.go. The scheme of whichever server actually answered a query
+// is preserved in the usedServer string LookupIPAddr returns, so callers
+// (HostResult.DNSServer) can see e.g. "tls://1.1.1.1:853" rather than just
+// an IP.
 type RRResolver struct {
-	servers []string
-	rr      uint32
+	transports []Transport
+	rr         uint32
+
+	// ecsSubnet, if non-nil, is attached as an EDNS0 Client Subnet option
+	// to every outgoing query; see Config.Transport's ClientSubnet/
+	// ECSFromInterface and resolveECSSubnet.
+	ecsSubnet *net.IPNet
+}
+
+// NewRRResolver builds a resolver from server descriptors as returned by
+// ParseServers (either bare "host:port" or URI-style, e.g. "tls://1.1.1.1:853").
+// Entries that fail to parse are skipped; quic:// and sdns:// entries are
+// kept, and every query sent to them fails (see NewTransport). If every
+// entry is unusable, the resolver falls back to Google's
+// public UDP servers so construction never fails outright on that account.
+// Hostname-based servers are bootstrapped against the default
+// BootstrapServers; it returns an error (rather than a nil *RRResolver) if
+// bootstrapping one of them fails, exactly like NewRRResolverWithConfig.
+// Use NewRRResolverWithConfig directly if any of them need a non-default
+// bootstrap list.
+func NewRRResolver(servers []string) (*RRResolver, error) {
+	return NewRRResolverWithConfig(servers, TransportConfig{}, nil)
 }
 
-func NewRRResolver(servers []string) *RRResolver {
+// NewRRResolverWithConfig is NewRRResolver with explicit per-transport
+// timeouts/pool sizes and EDNS0 options (see Config.Transport), plus the
+// plain DNS servers used to bootstrap any hostname-based upstream in
+// servers (see Config.BootstrapServers and bootstrap.go). It returns an
+// error rather than silently falling back whenever a hostname upstream
+// fails to bootstrap, so a misconfigured --dns surfaces at startup instead
+// of on every subsequent query.
+func NewRRResolverWithConfig(servers []string, tc TransportConfig, bootstrapServers []string) (*RRResolver, error) {
 	if len(servers) == 0 {
 		servers = []string{"8.8.8.8:53", "8.8.4.4:53"}
 	}
-	return &RRResolver{servers: servers}
+	boot := newBootstrapResolver(bootstrapServers)
+	ecsSubnet, err := resolveECSSubnet(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	var transports []Transport
+	for _, raw := range servers {
+		desc, err := ParseServerDescriptor(raw)
+		if err != nil {
+			continue
+		}
+		if descriptorNeedsBootstrap(desc) {
+			ip, err := boot.resolve(context.Background(), desc.Host)
+			if err != nil {
+				return nil, fmt.Errorf("bootstrap upstream %s: %w", desc.Raw, err)
+			}
+			desc.DialHost = ip.String()
+		}
+		t, err := NewTransport(desc, tc)
+		if err != nil {
+			continue
+		}
+		transports = append(transports, t)
+	}
+
+	if len(transports) == 0 {
+		for _, raw := range []string{"8.8.8.8:53", "8.8.4.4:53"} {
+			desc, _ := ParseServerDescriptor(raw)
+			if t, err := NewTransport(desc, tc); err == nil {
+				transports = append(transports, t)
+			}
+		}
+	}
+
+	return &RRResolver{transports: transports, ecsSubnet: ecsSubnet}, nil
+}
+
+// LookupIPAddr resolves both A and AAAA records for host against the next
+// upstream in round-robin order, returning the descriptor string of
+// whichever server actually answered and the EDNS Client Subnet value (if
+// any) attached to the queries.
+func (r *RRResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, string, string, error) {
+	if len(r.transports) == 0 {
+		return nil, "", "", errors.New("no DNS servers configured")
+	}
+
+	idx := int(atomic.AddUint32(&r.rr, 1)-1) % len(r.transports)
+	t := r.transports[idx]
+	ecsSent := ecsString(r.ecsSubnet)
+	usedServer := t.Descriptor().Raw
+
+	fqdn := dns.Fqdn(host)
+	var mu sync.Mutex
+	var ips []net.IPAddr
+	var firstErr error
+
+	var wg sync.WaitGroup
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		wg.Add(1)
+		go func(qtype uint16) {
+			defer wg.Done()
+			msg := new(dns.Msg)
+			msg.SetQuestion(fqdn, qtype)
+			msg.RecursionDesired = true
+			attachECS(msg, r.ecsSubnet)
+
+			resp, err := t.Exchange(ctx, msg)
+			if err == nil && resp != nil {
+				err = rcodeError(resp.Rcode)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			for _, rr := range resp.Answer {
+				switch v := rr.(type) {
+				case *dns.A:
+					ips = append(ips, net.IPAddr{IP: v.A})
+				case *dns.AAAA:
+					ips = append(ips, net.IPAddr{IP: v.AAAA})
+				}
+			}
+		}(qtype)
+	}
+	wg.Wait()
+
+	if len(ips) == 0 && firstErr != nil {
+		return nil, usedServer, ecsSent, firstErr
+	}
+	return ips, usedServer, ecsSent, nil
+}
+
+// recordTypeMap maps the record-type names accepted in Config.RecordTypes
+// (case-insensitively) to their miekg/dns query type constant.
+var recordTypeMap = map[string]uint16{
+	"MX":  dns.TypeMX,
+	"TXT": dns.TypeTXT,
+	"NS":  dns.TypeNS,
+	"SOA": dns.TypeSOA,
+	"CAA": dns.TypeCAA,
+	"SRV": dns.TypeSRV,
+}
+
+// LookupRecords queries host for each of the given record type names (see
+// recordTypeMap) against the next upstream in round-robin order, returning
+// the formatted rdata of every answer grouped by type name. Unknown type
+// names and per-type query failures are skipped rather than failing the
+// whole call, since Config.RecordTypes is a best-effort reconnaissance
+// extra rather than something callers should have to fail a host over.
+func (r *RRResolver) LookupRecords(ctx context.Context, host string, types []string) map[string][]string {
+	if len(types) == 0 || len(r.transports) == 0 {
+		return nil
+	}
+
+	idx := int(atomic.AddUint32(&r.rr, 1)-1) % len(r.transports)
+	t := r.transports[idx]
+	fqdn := dns.Fqdn(host)
+
+	var mu sync.Mutex
+	out := make(map[string][]string)
+	var wg sync.WaitGroup
+	for _, raw := range types {
+		name := strings.ToUpper(strings.TrimSpace(raw))
+		qtype, ok := recordTypeMap[name]
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(name string, qtype uint16) {
+			defer wg.Done()
+			msg := new(dns.Msg)
+			msg.SetQuestion(fqdn, qtype)
+			msg.RecursionDesired = true
+			attachECS(msg, r.ecsSubnet)
+
+			resp, err := t.Exchange(ctx, msg)
+			if err != nil || resp == nil || resp.Rcode != dns.RcodeSuccess {
+				return
+			}
+			values := make([]string, 0, len(resp.Answer))
+			for _, rr := range resp.Answer {
+				values = append(values, formatRRValue(rr))
+			}
+			if len(values) == 0 {
+				return
+			}
+			mu.Lock()
+			out[name] = values
+			mu.Unlock()
+		}(name, qtype)
+	}
+	wg.Wait()
+
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// LookupPTR issues a reverse-DNS (PTR) query for ip against the next
+// upstream in round-robin order, returning the hostname with the
+// trailing root dot stripped.
+func (r *RRResolver) LookupPTR(ctx context.Context, ip net.IP) (string, error) {
+	if len(r.transports) == 0 {
+		return "", errors.New("no DNS servers configured")
+	}
+	reverseName, err := dns.ReverseAddr(ip.String())
+	if err != nil {
+		return "", err
+	}
+
+	idx := int(atomic.AddUint32(&r.rr, 1)-1) % len(r.transports)
+	t := r.transports[idx]
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(reverseName, dns.TypePTR)
+	msg.RecursionDesired = true
+	attachECS(msg, r.ecsSubnet)
+
+	resp, err := t.Exchange(ctx, msg)
+	if err != nil {
+		return "", err
+	}
+	if err := rcodeError(resp.Rcode); err != nil {
+		return "", err
+	}
+	for _, rr := range resp.Answer {
+		if ptr, ok := rr.(*dns.PTR); ok {
+			return strings.TrimSuffix(ptr.Ptr, "."), nil
+		}
+	}
+	return "", errors.New("no PTR record")
+}
+
+// formatRRValue renders an answer RR's data (without the name/ttl/class
+// header) as a single string, in roughly the same field order `dig` prints.
+func formatRRValue(rr dns.RR) string {
+	switch v := rr.(type) {
+	case *dns.MX:
+		return fmt.Sprintf("%d %s", v.Preference, v.Mx)
+	case *dns.TXT:
+		return strings.Join(v.Txt, "")
+	case *dns.NS:
+		return v.Ns
+	case *dns.SOA:
+		return fmt.Sprintf("%s %s %d %d %d %d %d", v.Ns, v.Mbox, v.Serial, v.Refresh, v.Retry, v.Expire, v.Minttl)
+	case *dns.CAA:
+		return fmt.Sprintf("%d %s %q", v.Flag, v.Tag, v.Value)
+	case *dns.SRV:
+		return fmt.Sprintf("%d %d %d %s", v.Priority, v.Weight, v.Port, v.Target)
+	default:
+		return rr.String()
+	}
 }
 
-func (r *RRResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, string, error) {
-	if len(r.servers) == 0 {
-		return nil, "", errors.New("no DNS servers configured")
-	}
-	var usedServer string
-	resolver := &net.Resolver{
-		PreferGo: true,
-		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
-			idx := int(atomic.AddUint32(&r.rr, 1)-1) % len(r.servers)
-			usedServer = r.servers[idx]
-			d := &net.Dialer{Timeout: 2 * time.Second}
-			return d.DialContext(ctx, network, usedServer)
-		},
-	}
-	ips, err := resolver.LookupIPAddr(ctx, host)
-	return ips, usedServer, err
+// rcodeError turns a non-success DNS response code into an error whose
+// text matches what classifyLookupError expects (it used to see these as
+// net.Resolver errors).
+func rcodeError(rcode int) error {
+	switch rcode {
+	case dns.RcodeSuccess:
+		return nil
+	case dns.RcodeNameError:
+		return errors.New("no such host")
+	case dns.RcodeServerFailure:
+		return errors.New("server misbehaving")
+	case dns.RcodeRefused:
+		return errors.New("refused")
+	default:
+		return fmt.Errorf("dns rcode %s", dns.RcodeToString[rcode])
+	}
 }
 
+// ParseServers splits a comma-separated DNS server list into individual
+// server descriptors. Bare entries ("8.8.8.8" or "8.8.8.8:53") are assumed
+// to be plain UDP and get ":53" appended if no port is given. URI-style
+// entries (udp://, tcp://, tls://, https://, quic://, sdns://) are passed
+// through unchanged; their transport and default port are resolved later
+// by ParseServerDescriptor/NewTransport.
 func ParseServers(csv string) []string {
 	if strings.TrimSpace(csv) == "" {
 		return []string{"8.8.8.8:53", "8.8.4.4:53"}
@@ -111,7 +437,7 @@ func ParseServers(csv string) []string {
 		if p == "" {
 			continue
 		}
-		if !strings.Contains(p, ":") {
+		if !strings.Contains(p, "://") && !strings.Contains(p, ":") {
 			p = p + ":53"
 		}
 		out = append(out, p)
@@ -141,18 +467,53 @@ func classifyLookupError(err error) string {
 	}
 }
 
+// transportLabel derives the metrics label for the transport that answered a
+// query from the server descriptor string LookupIPAddr returns (e.g.
+// "tls://1.1.1.1:853" -> "tls"). It falls back to "udp" for bare host:port
+// descriptors and for anything that fails to parse.
+func transportLabel(usedServer string) string {
+	if usedServer == "" {
+		return "udp"
+	}
+	desc, err := ParseServerDescriptor(usedServer)
+	if err != nil {
+		return "udp"
+	}
+	return string(desc.Scheme)
+}
+
 // -------------- DB open/cache ----------
 
-var ipCache *expirable.LRU[string, IPEnriched]
+var (
+	enrichmentCache    EnrichmentCache
+	enrichmentCacheTTL time.Duration
+)
 
-func InitCache(size int, ttl time.Duration) {
+// InitCache builds the cache EnrichIP and CheckMaliciousDomain read and
+// write through. When cfg.CachePath is set, entries persist under that
+// directory (see fileEnrichmentCache) and survive across process
+// restarts; if that directory cannot be created, or CachePath is empty,
+// the default in-process LRU is used instead, sized by
+// cfg.IPCacheSize/cfg.IPCacheTTL as before. Safe to call once at startup,
+// mirroring InitWhois/InitBlocklists.
+func InitCache(cfg *Config) {
+	size := cfg.IPCacheSize
 	if size <= 0 {
 		size = 10000
 	}
+	ttl := cfg.IPCacheTTL
 	if ttl <= 0 {
 		ttl = 10 * time.Minute
 	}
-	ipCache = expirable.NewLRU[string, IPEnriched](size, nil, ttl)
+	enrichmentCacheTTL = ttl
+
+	if cfg.CachePath != "" {
+		if fc, err := newFileEnrichmentCache(cfg.CachePath); err == nil {
+			enrichmentCache = fc
+			return
+		}
+	}
+	enrichmentCache = newMemEnrichmentCache(size, ttl)
 }
 
 func OpenDBs(cfg *Config) (city *geoip2.Reader, asn *geoip2.Reader, err error) {
@@ -178,6 +539,12 @@ func OpenDBs(cfg *Config) (city *geoip2.Reader, asn *geoip2.Reader, err error) {
 
 // -------------- Malicious domain check -------------
 
+// quad9Server is Quad9's threat-intelligence resolver, queried by
+// CheckMaliciousDomain over whichever transport NewTransport builds for it
+// (plain DNS by default, but the descriptor could just as well be a DoT/DoH
+// URI if Quad9 ever needs to be reached that way).
+const quad9Server = "9.9.9.9:53"
+
 // CheckMaliciousDomain uses Quad9's threat intelligence to check if a domain is malicious.
 // Quad9 (9.9.9.9) blocks malicious domains by returning NXDOMAIN with RA flag set to 0.
 // We only check Quad9 if the domain resolved successfully with our regular resolvers.
@@ -186,6 +553,18 @@ func CheckMaliciousDomain(ctx context.Context, domain string, resolvedSuccessful
 		return false
 	}
 
+	if enrichmentCache != nil {
+		if raw, ok := enrichmentCache.Get("mal", domain); ok {
+			DefaultMetrics.ObserveCache(true)
+			var malicious bool
+			if json.Unmarshal(raw, &malicious) == nil {
+				return malicious
+			}
+		}
+	}
+	DefaultMetrics.ObserveCache(false)
+	DefaultMetrics.ObserveMaliciousCheck()
+
 	if timeout <= 0 {
 		timeout = 2 * time.Second
 	}
@@ -194,25 +573,51 @@ func CheckMaliciousDomain(ctx context.Context, domain string, resolvedSuccessful
 	msg.SetQuestion(dns.Fqdn(domain), dns.TypeA)
 	msg.RecursionDesired = true
 
-	client := &dns.Client{
-		Timeout: timeout,
+	desc, err := ParseServerDescriptor(quad9Server)
+	if err != nil {
+		return false
 	}
-
-	response, _, err := client.Exchange(msg, "9.9.9.9:53")
+	transport, err := NewTransport(desc, TransportConfig{DialTimeout: timeout})
 	if err != nil {
 		return false
 	}
 
-	if response.Rcode == dns.RcodeNameError && !response.RecursionAvailable {
-		return true
+	cctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	response, err := transport.Exchange(cctx, msg)
+	if err != nil {
+		return false
 	}
 
-	return false
+	malicious := response.Rcode == dns.RcodeNameError && !response.RecursionAvailable
+	if enrichmentCache != nil {
+		if raw, err := json.Marshal(malicious); err == nil {
+			enrichmentCache.Set("mal", domain, raw, enrichmentCacheTTL)
+		}
+	}
+	return malicious
 }
 
 // -------------- Core logic -------------
 
 func ResolveAndEnrichBatch(ctx context.Context, r *RRResolver, inputs []string, cfg *Config, cityDB *geoip2.Reader, asnDB *geoip2.Reader) ([]HostResult, error) {
+	results := make([]HostResult, len(inputs))
+	err := StreamResolveAndEnrichBatch(ctx, r, inputs, cfg, cityDB, asnDB, func(idx int, result HostResult) error {
+		results[idx] = result
+		return nil
+	})
+	return results, err
+}
+
+// StreamResolveAndEnrichBatch resolves and enriches every input exactly as
+// ResolveAndEnrichBatch does, but invokes emit(idx, result) as soon as each
+// host finishes instead of buffering the whole batch in memory. idx is the
+// host's position in inputs, so callers that don't need ordering (e.g. an
+// NDJSON HTTP response) can ignore it. emit is called from the goroutine
+// that resolved that host, so it must be safe for concurrent use; a
+// non-nil error from emit aborts that host's processing but does not stop
+// the rest of the batch.
+func StreamResolveAndEnrichBatch(ctx context.Context, r *RRResolver, inputs []string, cfg *Config, cityDB *geoip2.Reader, asnDB *geoip2.Reader, emit func(idx int, result HostResult) error) error {
 	timeout := cfg.LookupTimeout
 	if timeout <= 0 {
 		timeout = 2 * time.Second
@@ -221,10 +626,10 @@ func ResolveAndEnrichBatch(ctx context.Context, r *RRResolver, inputs []string,
 	if par <= 0 {
 		par = 64
 	}
-	results := make([]HostResult, len(inputs))
+
 	var whoisByDomain map[string]*WhoisToolInfo
 	var whoisErr string
-	if cfg.EnableWhois && cfg.WhoisToolPath != "" {
+	if cfg.EnableWhois {
 		domains := uniqueDomains(inputs)
 		if len(domains) > 0 {
 			perDomain := cfg.WhoisTimeout
@@ -239,7 +644,9 @@ func ResolveAndEnrichBatch(ctx context.Context, r *RRResolver, inputs []string,
 				toolTimeout = 5 * time.Minute
 			}
 			wctx, cancel := context.WithTimeout(ctx, toolTimeout)
-			info, err := RunWhoisTool(wctx, cfg.WhoisPython, cfg.WhoisToolPath, domains, toolTimeout)
+			whoisStart := time.Now()
+			info, err := LookupWhoisBatch(wctx, domains, cfg)
+			DefaultMetrics.ObserveWhois(time.Since(whoisStart))
 			cancel()
 			if err != nil {
 				whoisErr = err.Error()
@@ -262,22 +669,25 @@ func ResolveAndEnrichBatch(ctx context.Context, r *RRResolver, inputs []string,
 
 			host := strings.TrimSpace(strings.TrimSuffix(token, "."))
 			if host == "" {
-				results[idx] = HostResult{Domain: host, Resolved: false, Error: "lookup_failed"}
+				_ = emit(idx, HostResult{Domain: host, Resolved: false, Error: "lookup_failed"})
 				return
 			}
 
 			if ip := net.ParseIP(host); ip != nil {
-				info, _ := EnrichIP(ip, cityDB, asnDB)
-				results[idx] = HostResult{
+				info, _ := EnrichIP(ctx, r, ip, cityDB, asnDB, cfg)
+				_ = emit(idx, HostResult{
 					Domain:   host,
 					Resolved: true,
 					IPs:      []IPEnriched{info},
-				}
+				})
 				return
 			}
 
+			activeR, group, rule := routeFor(r, host)
+
 			cctx, cancel := context.WithTimeout(ctx, timeout)
-			addrs, usedServer, err := r.LookupIPAddr(cctx, host)
+			queryStart := time.Now()
+			addrs, usedServer, ecsSent, err := activeR.LookupIPAddr(cctx, host)
 			cancel()
 
 			var errText string
@@ -287,6 +697,7 @@ func ResolveAndEnrichBatch(ctx context.Context, r *RRResolver, inputs []string,
 			if errText == "" && len(addrs) == 0 {
 				errText = "no_records"
 			}
+			DefaultMetrics.ObserveDNSQuery(transportLabel(usedServer), time.Since(queryStart), errText)
 
 			var maliciousPtr *bool
 			if cfg.CheckMalicious && len(addrs) > 0 {
@@ -297,17 +708,20 @@ func ResolveAndEnrichBatch(ctx context.Context, r *RRResolver, inputs []string,
 			uniq := unique(addrs, cfg.PreferIPv6)
 			enriched := make([]IPEnriched, 0, len(uniq))
 			for _, a := range uniq {
-				info, _ := EnrichIP(a.IP, cityDB, asnDB)
+				info, _ := EnrichIP(ctx, activeR, a.IP, cityDB, asnDB, cfg)
 				enriched = append(enriched, info)
 			}
 
 			result := HostResult{
-				Domain:    host,
-				Resolved:  len(enriched) > 0,
-				DNSServer: usedServer,
-				Malicious: maliciousPtr,
-				IPs:       enriched,
-				Error:     errText,
+				Domain:        host,
+				Resolved:      len(enriched) > 0,
+				DNSServer:     usedServer,
+				Malicious:     maliciousPtr,
+				IPs:           enriched,
+				Route:         rule,
+				UpstreamGroup: group,
+				ClientSubnet:  ecsSent,
+				Error:         errText,
 			}
 			if whoisByDomain != nil {
 				if info, ok := whoisByDomain[host]; ok {
@@ -318,12 +732,18 @@ func ResolveAndEnrichBatch(ctx context.Context, r *RRResolver, inputs []string,
 			} else if whoisErr != "" {
 				result.WhoisError = whoisErr
 			}
-			results[idx] = result
+			if len(cfg.RecordTypes) > 0 {
+				rctx, rcancel := context.WithTimeout(ctx, timeout)
+				result.Records = activeR.LookupRecords(rctx, host, cfg.RecordTypes)
+				rcancel()
+			}
+			checkBlocklists(cfg, host, &result)
+			_ = emit(idx, result)
 		}(i, raw)
 	}
 
 	wg.Wait()
-	return results, nil
+	return nil
 }
 
 func unique(in []net.IPAddr, preferV6 bool) []net.IPAddr {
@@ -347,13 +767,31 @@ func unique(in []net.IPAddr, preferV6 bool) []net.IPAddr {
 	return out
 }
 
-func EnrichIP(ip net.IP, cityDB *geoip2.Reader, asnDB *geoip2.Reader) (IPEnriched, error) {
+// EnrichIP looks up ip's geo/ASN data (via cityDB/asnDB) and, when
+// cfg.ReverseLookup is set, its PTR record through r, memoizing the
+// combined result in the enrichment cache. A PTR failure degrades to an
+// empty IPEnriched.PTR rather than failing the whole lookup.
+func EnrichIP(ctx context.Context, r *RRResolver, ip net.IP, cityDB *geoip2.Reader, asnDB *geoip2.Reader, cfg *Config) (IPEnriched, error) {
 	key := ip.String()
-	if ipCache != nil {
-		if v, ok := ipCache.Get(key); ok {
-			return v, nil
+	cacheKey := key
+	if cfg != nil && cfg.ReverseLookup {
+		// Keyed separately from a non-reverse-lookup run so a cached entry
+		// without PTR/ForwardConfirmed (from a run started without
+		// --reverse-lookup) isn't served back once --reverse-lookup is
+		// turned on, until enrichmentCacheTTL would have expired it anyway.
+		cacheKey += "|rdns"
+	}
+	if enrichmentCache != nil {
+		if raw, ok := enrichmentCache.Get("enrich", cacheKey); ok {
+			var v IPEnriched
+			if err := json.Unmarshal(raw, &v); err == nil {
+				DefaultMetrics.ObserveCache(true)
+				return v, nil
+			}
 		}
 	}
+	DefaultMetrics.ObserveCache(false)
+	DefaultMetrics.ObserveGeoIPLookup()
 	info := IPEnriched{IP: key}
 	if ip.To4() != nil {
 		info.Family = "v4"
@@ -387,8 +825,34 @@ func EnrichIP(ip net.IP, cityDB *geoip2.Reader, asnDB *geoip2.Reader) (IPEnriche
 		}
 	}
 
-	if ipCache != nil {
-		ipCache.Add(key, info)
+	if cfg != nil && cfg.ReverseLookup && r != nil {
+		timeout := cfg.LookupTimeout
+		if timeout <= 0 {
+			timeout = 2 * time.Second
+		}
+		pctx, cancel := context.WithTimeout(ctx, timeout)
+		ptr, err := r.LookupPTR(pctx, ip)
+		cancel()
+		if err == nil {
+			info.PTR = ptr
+			fctx, fcancel := context.WithTimeout(ctx, timeout)
+			addrs, _, _, err := r.LookupIPAddr(fctx, ptr)
+			fcancel()
+			if err == nil {
+				for _, a := range addrs {
+					if a.IP.Equal(ip) {
+						info.ForwardConfirmed = true
+						break
+					}
+				}
+			}
+		}
+	}
+
+	if enrichmentCache != nil {
+		if raw, err := json.Marshal(info); err == nil {
+			enrichmentCache.Set("enrich", cacheKey, raw, enrichmentCacheTTL)
+		}
 	}
 	return info, nil
 }
@@ -0,0 +1,39 @@
+package dnsgeeo
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+// TestEnrichIPCacheKeyIsolatesReverseLookup guards against a regression
+// where EnrichIP cached entries purely by IP, so a run started without
+// --reverse-lookup would poison the cache for a later run with it enabled.
+func TestEnrichIPCacheKeyIsolatesReverseLookup(t *testing.T) {
+	prevCache, prevTTL := enrichmentCache, enrichmentCacheTTL
+	enrichmentCache = newMemEnrichmentCache(10, time.Minute)
+	enrichmentCacheTTL = time.Minute
+	t.Cleanup(func() {
+		enrichmentCache, enrichmentCacheTTL = prevCache, prevTTL
+	})
+
+	ip := net.ParseIP("203.0.113.5")
+
+	if _, err := EnrichIP(context.Background(), nil, ip, nil, nil, &Config{ReverseLookup: false}); err != nil {
+		t.Fatalf("EnrichIP (no reverse lookup): %v", err)
+	}
+	if _, ok := enrichmentCache.Get("enrich", "203.0.113.5"); !ok {
+		t.Fatal("expected a cache entry keyed by bare IP")
+	}
+	if _, ok := enrichmentCache.Get("enrich", "203.0.113.5|rdns"); ok {
+		t.Fatal("did not expect a reverse-lookup cache entry yet")
+	}
+
+	if _, err := EnrichIP(context.Background(), nil, ip, nil, nil, &Config{ReverseLookup: true}); err != nil {
+		t.Fatalf("EnrichIP (reverse lookup): %v", err)
+	}
+	if _, ok := enrichmentCache.Get("enrich", "203.0.113.5|rdns"); !ok {
+		t.Fatal("expected a separate cache entry keyed by IP+reverse-lookup state")
+	}
+}
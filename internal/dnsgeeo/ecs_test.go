@@ -0,0 +1,97 @@
+package dnsgeeo
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestResolveECSSubnetFromClientSubnet(t *testing.T) {
+	subnet, err := resolveECSSubnet(TransportConfig{ClientSubnet: "203.0.113.0/24"})
+	if err != nil {
+		t.Fatalf("resolveECSSubnet: %v", err)
+	}
+	if subnet == nil {
+		t.Fatal("expected a non-nil subnet")
+	}
+	if !subnet.IP.Equal(net.ParseIP("203.0.113.0")) {
+		t.Errorf("subnet.IP = %s, want 203.0.113.0", subnet.IP)
+	}
+	ones, _ := subnet.Mask.Size()
+	if ones != 24 {
+		t.Errorf("mask = /%d, want /24", ones)
+	}
+}
+
+func TestResolveECSSubnetRejectsInvalidCIDR(t *testing.T) {
+	if _, err := resolveECSSubnet(TransportConfig{ClientSubnet: "not-a-cidr"}); err == nil {
+		t.Fatal("expected an error for a malformed client subnet")
+	}
+}
+
+func TestResolveECSSubnetNoneConfigured(t *testing.T) {
+	subnet, err := resolveECSSubnet(TransportConfig{})
+	if err != nil {
+		t.Fatalf("resolveECSSubnet: %v", err)
+	}
+	if subnet != nil {
+		t.Errorf("subnet = %v, want nil when neither ClientSubnet nor ECSFromInterface is set", subnet)
+	}
+}
+
+// TestAttachECSAddsEDNS0SubnetOption guards the wire format of the ECS
+// option dnsgeeo attaches to outgoing queries: family/netmask/address must
+// match the configured subnet so upstream CDN resolvers see the intended
+// client location.
+func TestAttachECSAddsEDNS0SubnetOption(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("203.0.113.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := new(dns.Msg)
+	attachECS(msg, subnet)
+
+	if len(msg.Extra) != 1 {
+		t.Fatalf("len(msg.Extra) = %d, want 1", len(msg.Extra))
+	}
+	opt, ok := msg.Extra[0].(*dns.OPT)
+	if !ok {
+		t.Fatalf("msg.Extra[0] = %T, want *dns.OPT", msg.Extra[0])
+	}
+	if len(opt.Option) != 1 {
+		t.Fatalf("len(opt.Option) = %d, want 1", len(opt.Option))
+	}
+	ecs, ok := opt.Option[0].(*dns.EDNS0_SUBNET)
+	if !ok {
+		t.Fatalf("opt.Option[0] = %T, want *dns.EDNS0_SUBNET", opt.Option[0])
+	}
+	if ecs.Family != 1 {
+		t.Errorf("Family = %d, want 1 (IPv4)", ecs.Family)
+	}
+	if ecs.SourceNetmask != 24 {
+		t.Errorf("SourceNetmask = %d, want 24", ecs.SourceNetmask)
+	}
+	if !ecs.Address.Equal(net.ParseIP("203.0.113.0")) {
+		t.Errorf("Address = %s, want 203.0.113.0", ecs.Address)
+	}
+}
+
+func TestAttachECSNoopWithNilSubnet(t *testing.T) {
+	msg := new(dns.Msg)
+	attachECS(msg, nil)
+	if len(msg.Extra) != 0 {
+		t.Errorf("expected no EDNS0 option when subnet is nil, got %d entries", len(msg.Extra))
+	}
+}
+
+func TestECSString(t *testing.T) {
+	if got := ecsString(nil); got != "" {
+		t.Errorf("ecsString(nil) = %q, want empty", got)
+	}
+	_, subnet, _ := net.ParseCIDR("203.0.113.0/24")
+	if got := ecsString(subnet); got != "203.0.113.0/24" {
+		t.Errorf("ecsString(subnet) = %q, want 203.0.113.0/24", got)
+	}
+}
@@ -0,0 +1,445 @@
+package dnsgeeo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	geoip2 "github.com/oschwald/geoip2-golang"
+)
+
+// defaultWordlist is used when EnumerateConfig.Wordlist is empty. It is
+// deliberately small; callers after real coverage should pass --wordlist.
+var defaultWordlist = []string{
+	"www", "mail", "ftp", "admin", "api", "dev", "staging", "test", "vpn",
+	"ns1", "ns2", "smtp", "webmail", "portal", "beta", "m", "blog", "shop",
+	"cdn", "static", "app", "git", "internal", "remote", "support",
+}
+
+// permutationTokens are prepended or appended to the first label of
+// already-known names to find sibling hosts (dev-api.example.com,
+// api-dev.example.com, ...); they are never substituted for an existing
+// label or token.
+var permutationTokens = []string{"dev", "stage", "staging", "test", "qa", "api", "internal", "old", "new", "backup"}
+
+// CTSource looks up certificate-transparency logged names for a domain.
+// The default implementation queries crt.sh; callers can supply their own
+// (e.g. to hit a different CT aggregator or a local mirror).
+type CTSource interface {
+	Subdomains(ctx context.Context, domain string) ([]string, error)
+}
+
+// EnumerateConfig controls which discovery sources EnumerateBatch runs.
+type EnumerateConfig struct {
+	Wordlist         []string
+	Permutations     bool
+	ReverseSweep     bool
+	ReverseSweepCIDR int // prefix length around each resolved IP, e.g. 24
+	TryAXFR          bool
+	CTSource         CTSource // nil disables CT scraping
+	EnumDepth        int      // rounds of feeding discoveries back into resolution
+}
+
+func (c EnumerateConfig) wordlistOrDefault() []string {
+	if len(c.Wordlist) > 0 {
+		return c.Wordlist
+	}
+	return defaultWordlist
+}
+
+func (c EnumerateConfig) reverseSweepPrefix() int {
+	if c.ReverseSweepCIDR > 0 {
+		return c.ReverseSweepCIDR
+	}
+	return 24
+}
+
+func (c EnumerateConfig) depthOrDefault() int {
+	if c.EnumDepth > 0 {
+		return c.EnumDepth
+	}
+	return 1
+}
+
+// CrtSHSource is the default CTSource, backed by crt.sh's JSON API.
+type CrtSHSource struct {
+	HTTPClient *http.Client
+}
+
+type crtSHEntry struct {
+	NameValue string `json:"name_value"`
+}
+
+// Subdomains queries crt.sh for certificates covering "%.domain" and returns
+// the distinct hostnames found across all matching name_value entries.
+func (s *CrtSHSource) Subdomains(ctx context.Context, domain string) ([]string, error) {
+	client := s.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+
+	url := "https://crt.sh/?q=%25." + domain + "&output=json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build crt.sh request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("query crt.sh for %s: %w", domain, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("query crt.sh for %s: unexpected status %d", domain, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16*1024*1024))
+	if err != nil {
+		return nil, fmt.Errorf("read crt.sh response: %w", err)
+	}
+
+	var entries []crtSHEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("parse crt.sh response: %w", err)
+	}
+
+	seen := map[string]struct{}{}
+	var out []string
+	for _, e := range entries {
+		for _, name := range strings.Split(e.NameValue, "\n") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			name = strings.TrimPrefix(name, "*.")
+			if name == "" {
+				continue
+			}
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			out = append(out, name)
+		}
+	}
+	return out, nil
+}
+
+// candidate is a discovered (but not yet resolved) hostname, tagged with
+// the source that produced it.
+type candidate struct {
+	host string
+	via  string
+}
+
+// EnumerateBatch expands roots into a discovered subdomain set using the
+// sources enabled in enumCfg, resolving each discovered host and enriching
+// its IPs (GeoIP/ASN, optional PTR, optional extra record types). Unlike
+// ResolveAndEnrichBatch it does not run the Quad9 malicious check, WHOIS,
+// or blocklist annotation on these results. Every result's DiscoveredVia
+// field records which source first surfaced it ("root" for the seed
+// domains themselves).
+func EnumerateBatch(ctx context.Context, r *RRResolver, roots []string, cfg *Config, enumCfg EnumerateConfig, cityDB, asnDB *geoip2.Reader) ([]HostResult, error) {
+	par := cfg.Parallelism
+	if par <= 0 {
+		par = 64
+	}
+	timeout := cfg.LookupTimeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+
+	var (
+		mu       sync.Mutex
+		seen     = map[string]struct{}{}
+		results  []HostResult
+		resolved []string // names that resolved, used as the base for permutations/reverse sweep
+	)
+
+	resolveOne := func(host, via string) HostResult {
+		activeR, group, rule := routeFor(r, host)
+
+		cctx, cancel := context.WithTimeout(ctx, timeout)
+		addrs, usedServer, ecsSent, err := activeR.LookupIPAddr(cctx, host)
+		cancel()
+
+		var errText string
+		if err != nil {
+			errText = classifyLookupError(err)
+		}
+		if errText == "" && len(addrs) == 0 {
+			errText = "no_records"
+		}
+
+		uniq := unique(addrs, cfg.PreferIPv6)
+		enriched := make([]IPEnriched, 0, len(uniq))
+		for _, a := range uniq {
+			info, _ := EnrichIP(ctx, activeR, a.IP, cityDB, asnDB, cfg)
+			enriched = append(enriched, info)
+		}
+
+		result := HostResult{
+			Domain:        host,
+			Resolved:      len(enriched) > 0,
+			DNSServer:     usedServer,
+			DiscoveredVia: via,
+			IPs:           enriched,
+			Route:         rule,
+			UpstreamGroup: group,
+			ClientSubnet:  ecsSent,
+			Error:         errText,
+		}
+		if len(cfg.RecordTypes) > 0 {
+			rctx, rcancel := context.WithTimeout(ctx, timeout)
+			result.Records = activeR.LookupRecords(rctx, host, cfg.RecordTypes)
+			rcancel()
+		}
+		return result
+	}
+
+	sem := make(chan struct{}, par)
+	var wg sync.WaitGroup
+	runBatch := func(cands []candidate) {
+		for _, c := range cands {
+			mu.Lock()
+			if _, dup := seen[c.host]; dup {
+				mu.Unlock()
+				continue
+			}
+			seen[c.host] = struct{}{}
+			mu.Unlock()
+
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(c candidate) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				result := resolveOne(c.host, c.via)
+				mu.Lock()
+				results = append(results, result)
+				if result.Resolved {
+					resolved = append(resolved, c.host)
+				}
+				mu.Unlock()
+			}(c)
+		}
+		wg.Wait()
+	}
+
+	var seedCands []candidate
+	for _, root := range roots {
+		seedCands = append(seedCands, candidate{host: strings.ToLower(strings.TrimSuffix(strings.TrimSpace(root), ".")), via: "root"})
+	}
+	runBatch(seedCands)
+
+	for depth := 0; depth < enumCfg.depthOrDefault(); depth++ {
+		var next []candidate
+
+		for _, root := range roots {
+			root = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(root), "."))
+			for _, w := range enumCfg.wordlistOrDefault() {
+				next = append(next, candidate{host: w + "." + root, via: "wordlist"})
+			}
+			if enumCfg.TryAXFR {
+				next = append(next, axfrCandidates(ctx, r, root)...)
+			}
+			if enumCfg.CTSource != nil {
+				if names, err := enumCfg.CTSource.Subdomains(ctx, root); err == nil {
+					for _, n := range names {
+						next = append(next, candidate{host: n, via: "crtsh"})
+					}
+				}
+			}
+		}
+
+		if enumCfg.Permutations {
+			mu.Lock()
+			base := append([]string(nil), resolved...)
+			mu.Unlock()
+			next = append(next, permute(base)...)
+		}
+
+		if enumCfg.ReverseSweep {
+			mu.Lock()
+			lastResults := append([]HostResult(nil), results...)
+			mu.Unlock()
+			next = append(next, reverseSweepCandidates(ctx, r, lastResults, enumCfg.reverseSweepPrefix(), par)...)
+		}
+
+		if len(next) == 0 {
+			break
+		}
+		runBatch(next)
+	}
+
+	return results, nil
+}
+
+// permute generates sibling-name candidates from already-resolved hosts by
+// prepending/appending permutationTokens and sweeping a trailing digit.
+func permute(hosts []string) []candidate {
+	var out []candidate
+	for _, host := range hosts {
+		parts := strings.SplitN(host, ".", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		label, rest := parts[0], parts[1]
+		for _, tok := range permutationTokens {
+			out = append(out,
+				candidate{host: tok + "-" + label + "." + rest, via: "permutation"},
+				candidate{host: label + "-" + tok + "." + rest, via: "permutation"},
+			)
+		}
+		for i := 0; i < 10; i++ {
+			out = append(out, candidate{host: label + strconv.Itoa(i) + "." + rest, via: "permutation"})
+		}
+	}
+	return out
+}
+
+// axfrCandidates discovers the authoritative nameservers for domain via NS
+// lookup and attempts a zone transfer against each; successful transfers
+// yield every name in the zone.
+func axfrCandidates(ctx context.Context, r *RRResolver, domain string) []candidate {
+	if len(r.transports) == 0 {
+		return nil
+	}
+
+	nsMsg := new(dns.Msg)
+	nsMsg.SetQuestion(dns.Fqdn(domain), dns.TypeNS)
+	nsMsg.RecursionDesired = true
+	resp, err := r.transports[0].Exchange(ctx, nsMsg)
+	if err != nil || resp == nil {
+		return nil
+	}
+
+	var out []candidate
+	for _, rr := range resp.Answer {
+		ns, ok := rr.(*dns.NS)
+		if !ok {
+			continue
+		}
+		out = append(out, tryAXFR(ctx, domain, strings.TrimSuffix(ns.Ns, "."))...)
+	}
+	return out
+}
+
+func tryAXFR(ctx context.Context, domain, nsHost string) []candidate {
+	tr := &dns.Transfer{DialTimeout: 2 * time.Second, ReadTimeout: 5 * time.Second}
+	msg := new(dns.Msg)
+	msg.SetAxfr(dns.Fqdn(domain))
+
+	envCh, err := tr.In(msg, net.JoinHostPort(nsHost, "53"))
+	if err != nil {
+		return nil
+	}
+
+	var out []candidate
+	for env := range envCh {
+		if env.Error != nil {
+			break
+		}
+		for _, rr := range env.RR {
+			name := strings.TrimSuffix(rr.Header().Name, ".")
+			if name != "" && name != domain {
+				out = append(out, candidate{host: name, via: "axfr"})
+			}
+		}
+	}
+	return out
+}
+
+// reverseSweepCandidates issues PTR queries across the configured CIDR
+// prefix around every IPv4 address in results (at most the first 256
+// addresses of each network, so a prefix shorter than /24 is only
+// partly swept), surfacing any hostnames found next to
+// already-resolved infrastructure. Lookups go through r (so they honor
+// --dns/DoT/DoH routing like every other query this package issues) and run
+// up to par at a time, since a /24 sweep means up to 256 PTR queries.
+func reverseSweepCandidates(ctx context.Context, r *RRResolver, results []HostResult, prefixLen, par int) []candidate {
+	seenNets := map[string]struct{}{}
+	var targets []net.IP
+
+	for _, res := range results {
+		for _, ip := range res.IPs {
+			parsed := net.ParseIP(ip.IP)
+			if parsed == nil || parsed.To4() == nil {
+				continue // IPv6 /24-style sweeps are impractically large; skip
+			}
+			_, network, err := net.ParseCIDR(fmt.Sprintf("%s/%d", parsed.String(), prefixLen))
+			if err != nil {
+				continue
+			}
+			key := network.String()
+			if _, ok := seenNets[key]; ok {
+				continue
+			}
+			seenNets[key] = struct{}{}
+			targets = append(targets, hostsInNetwork(network, 256)...)
+		}
+	}
+
+	if par <= 0 {
+		par = 64
+	}
+
+	var (
+		mu  sync.Mutex
+		out []candidate
+		wg  sync.WaitGroup
+	)
+	sem := make(chan struct{}, par)
+	for _, ip := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(ip net.IP) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			cctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			name, err := r.LookupPTR(cctx, ip)
+			cancel()
+			if err != nil || name == "" {
+				return
+			}
+			mu.Lock()
+			out = append(out, candidate{host: strings.TrimSuffix(strings.ToLower(name), "."), via: "reverse-dns"})
+			mu.Unlock()
+		}(ip)
+	}
+	wg.Wait()
+	return out
+}
+
+// hostsInNetwork enumerates up to limit addresses in network, starting at
+// the network address; the network and broadcast addresses are included.
+func hostsInNetwork(network *net.IPNet, limit int) []net.IP {
+	var out []net.IP
+	ip := network.IP.Mask(network.Mask)
+	for i := 0; i < limit; i++ {
+		next := make(net.IP, len(ip))
+		copy(next, ip)
+		out = append(out, next)
+		incIP(ip)
+		if !network.Contains(ip) {
+			break
+		}
+	}
+	return out
+}
+
+func incIP(ip net.IP) {
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]++
+		if ip[i] != 0 {
+			break
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package dnsgeeo
+
+import (
+	"net"
+	"testing"
+)
+
+func TestPermuteGeneratesTokenAndDigitSiblings(t *testing.T) {
+	cands := permute([]string{"api.example.com"})
+
+	wantHosts := map[string]bool{
+		"dev-api.example.com": false,
+		"api-dev.example.com": false,
+		"api5.example.com":    false,
+	}
+	for _, c := range cands {
+		if c.via != "permutation" {
+			t.Errorf("candidate %q via = %q, want %q", c.host, c.via, "permutation")
+		}
+		if _, ok := wantHosts[c.host]; ok {
+			wantHosts[c.host] = true
+		}
+	}
+	for host, found := range wantHosts {
+		if !found {
+			t.Errorf("expected permute to produce %q, got %+v", host, cands)
+		}
+	}
+}
+
+func TestPermuteSkipsBareHostnames(t *testing.T) {
+	if cands := permute([]string{"localhost"}); cands != nil {
+		t.Errorf("expected no candidates for a label with no dot, got %+v", cands)
+	}
+}
+
+func TestHostsInNetworkStopsAtBoundary(t *testing.T) {
+	_, network, err := net.ParseCIDR("203.0.113.0/30")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hosts := hostsInNetwork(network, 256)
+	if len(hosts) != 4 {
+		t.Fatalf("len(hosts) = %d, want 4 for a /30", len(hosts))
+	}
+	if !hosts[0].Equal(net.ParseIP("203.0.113.0")) {
+		t.Errorf("hosts[0] = %s, want 203.0.113.0", hosts[0])
+	}
+	if !hosts[3].Equal(net.ParseIP("203.0.113.3")) {
+		t.Errorf("hosts[3] = %s, want 203.0.113.3", hosts[3])
+	}
+}
+
+func TestIncIPCarriesAcrossOctets(t *testing.T) {
+	ip := net.ParseIP("203.0.113.255").To4()
+	incIP(ip)
+	if !ip.Equal(net.ParseIP("203.0.114.0")) {
+		t.Errorf("incIP carry = %s, want 203.0.114.0", ip)
+	}
+}
@@ -0,0 +1,195 @@
+package dnsgeeo
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dnsLatencyBucketsSeconds are the upper bounds (in seconds) of the
+// Prometheus histogram buckets used for dnsgeeo_dns_latency_seconds. They
+// span typical DNS round-trips from cache-hit-fast (1ms) to
+// badly-congested-upstream (10s).
+var dnsLatencyBucketsSeconds = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// dnsLatencyHistogram accumulates one Prometheus histogram's worth of
+// observations (per-bucket cumulative counts, sum, count) for a single
+// transport label. All methods are safe for concurrent use.
+type dnsLatencyHistogram struct {
+	mu      sync.Mutex
+	buckets []int64 // buckets[i] = count of observations <= dnsLatencyBucketsSeconds[i]
+	sum     float64
+	count   int64
+}
+
+func newDNSLatencyHistogram() *dnsLatencyHistogram {
+	return &dnsLatencyHistogram{buckets: make([]int64, len(dnsLatencyBucketsSeconds))}
+}
+
+func (h *dnsLatencyHistogram) observe(d time.Duration) {
+	seconds := d.Seconds()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sum += seconds
+	h.count++
+	for i, bound := range dnsLatencyBucketsSeconds {
+		if seconds <= bound {
+			h.buckets[i]++
+		}
+	}
+}
+
+func (h *dnsLatencyHistogram) snapshot() (buckets []int64, sum float64, count int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	buckets = make([]int64, len(h.buckets))
+	copy(buckets, h.buckets)
+	return buckets, h.sum, h.count
+}
+
+// Metrics accumulates counters and latency totals for everything
+// ResolveAndEnrichBatch/StreamResolveAndEnrichBatch does, so a --serve
+// process can expose them on /metrics in Prometheus text format. All
+// fields are safe for concurrent use; a single process-wide instance
+// (DefaultMetrics) is updated by the core resolve path.
+type Metrics struct {
+	dnsQueriesTotal sync.Map // transport -> *int64
+	dnsLatency      sync.Map // transport -> *dnsLatencyHistogram
+	dnsErrorsTotal  sync.Map // error class -> *int64
+
+	cacheHits   int64
+	cacheMisses int64
+
+	whoisLookupsTotal   int64
+	whoisLatencyTotalNS int64
+
+	geoipLookupsTotal int64
+
+	maliciousChecksTotal int64
+}
+
+// DefaultMetrics is the process-wide Metrics instance the resolve path
+// updates; Config has no field for supplying a different one.
+var DefaultMetrics = NewMetrics()
+
+// NewMetrics returns a zeroed Metrics collector.
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+func (m *Metrics) counter(store *sync.Map, key string, delta int64) {
+	if m == nil {
+		return
+	}
+	v, _ := store.LoadOrStore(key, new(int64))
+	atomic.AddInt64(v.(*int64), delta)
+}
+
+// ObserveDNSQuery records one upstream query's outcome and latency for the
+// given transport scheme ("udp", "tcp", "tls", "https", "quic").
+func (m *Metrics) ObserveDNSQuery(transport string, d time.Duration, errClass string) {
+	if m == nil {
+		return
+	}
+	if transport == "" {
+		transport = "udp"
+	}
+	m.counter(&m.dnsQueriesTotal, transport, 1)
+	v, _ := m.dnsLatency.LoadOrStore(transport, newDNSLatencyHistogram())
+	v.(*dnsLatencyHistogram).observe(d)
+	if errClass != "" {
+		m.counter(&m.dnsErrorsTotal, errClass, 1)
+	}
+}
+
+// ObserveCache records an enrichment cache hit or miss.
+func (m *Metrics) ObserveCache(hit bool) {
+	if m == nil {
+		return
+	}
+	if hit {
+		atomic.AddInt64(&m.cacheHits, 1)
+	} else {
+		atomic.AddInt64(&m.cacheMisses, 1)
+	}
+}
+
+// ObserveWhois records the latency of one native WHOIS/RDAP batch lookup
+// (a single LookupWhoisBatch call covering every domain of a resolve batch).
+func (m *Metrics) ObserveWhois(d time.Duration) {
+	if m == nil {
+		return
+	}
+	atomic.AddInt64(&m.whoisLookupsTotal, 1)
+	atomic.AddInt64(&m.whoisLatencyTotalNS, d.Nanoseconds())
+}
+
+// ObserveGeoIPLookup records one MaxMind DB lookup.
+func (m *Metrics) ObserveGeoIPLookup() {
+	if m == nil {
+		return
+	}
+	atomic.AddInt64(&m.geoipLookupsTotal, 1)
+}
+
+// ObserveMaliciousCheck records one Quad9 malicious-domain check that
+// missed the cache; blocklist matches are not counted here.
+func (m *Metrics) ObserveMaliciousCheck() {
+	if m == nil {
+		return
+	}
+	atomic.AddInt64(&m.maliciousChecksTotal, 1)
+}
+
+// WritePrometheus writes all counters in Prometheus text exposition format,
+// plus the on-disk age of cityDBPath/asnDBPath (if set) as a gauge.
+func (m *Metrics) WritePrometheus(w *strings.Builder, cityDBPath, asnDBPath string) {
+	writeCounter := func(name, help string, store *sync.Map) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		store.Range(func(k, v any) bool {
+			fmt.Fprintf(w, "%s{label=%q} %d\n", name, k.(string), atomic.LoadInt64(v.(*int64)))
+			return true
+		})
+	}
+
+	writeCounter("dnsgeeo_dns_queries_total", "Total DNS queries sent, by transport.", &m.dnsQueriesTotal)
+	writeCounter("dnsgeeo_dns_errors_total", "Total DNS query errors, by error class.", &m.dnsErrorsTotal)
+
+	fmt.Fprintf(w, "# HELP dnsgeeo_dns_latency_seconds DNS query latency distribution, by transport.\n# TYPE dnsgeeo_dns_latency_seconds histogram\n")
+	m.dnsLatency.Range(func(k, v any) bool {
+		transport := k.(string)
+		buckets, sum, count := v.(*dnsLatencyHistogram).snapshot()
+		for i, bound := range dnsLatencyBucketsSeconds {
+			fmt.Fprintf(w, "dnsgeeo_dns_latency_seconds_bucket{transport=%q,le=%q} %d\n", transport, strconv.FormatFloat(bound, 'g', -1, 64), buckets[i])
+		}
+		fmt.Fprintf(w, "dnsgeeo_dns_latency_seconds_bucket{transport=%q,le=\"+Inf\"} %d\n", transport, count)
+		fmt.Fprintf(w, "dnsgeeo_dns_latency_seconds_sum{transport=%q} %f\n", transport, sum)
+		fmt.Fprintf(w, "dnsgeeo_dns_latency_seconds_count{transport=%q} %d\n", transport, count)
+		return true
+	})
+
+	fmt.Fprintf(w, "# HELP dnsgeeo_cache_hits_total Enrichment cache hits.\n# TYPE dnsgeeo_cache_hits_total counter\ndnsgeeo_cache_hits_total %d\n", atomic.LoadInt64(&m.cacheHits))
+	fmt.Fprintf(w, "# HELP dnsgeeo_cache_misses_total Enrichment cache misses.\n# TYPE dnsgeeo_cache_misses_total counter\ndnsgeeo_cache_misses_total %d\n", atomic.LoadInt64(&m.cacheMisses))
+	fmt.Fprintf(w, "# HELP dnsgeeo_whois_lookups_total Native WHOIS/RDAP batch lookups performed (one per resolve batch).\n# TYPE dnsgeeo_whois_lookups_total counter\ndnsgeeo_whois_lookups_total %d\n", atomic.LoadInt64(&m.whoisLookupsTotal))
+	fmt.Fprintf(w, "# HELP dnsgeeo_whois_latency_seconds_sum Cumulative WHOIS/RDAP batch lookup latency.\n# TYPE dnsgeeo_whois_latency_seconds_sum counter\ndnsgeeo_whois_latency_seconds_sum %f\n", time.Duration(atomic.LoadInt64(&m.whoisLatencyTotalNS)).Seconds())
+	fmt.Fprintf(w, "# HELP dnsgeeo_geoip_lookups_total MaxMind DB lookups performed.\n# TYPE dnsgeeo_geoip_lookups_total counter\ndnsgeeo_geoip_lookups_total %d\n", atomic.LoadInt64(&m.geoipLookupsTotal))
+	fmt.Fprintf(w, "# HELP dnsgeeo_malicious_checks_total Quad9 malicious-domain checks performed.\n# TYPE dnsgeeo_malicious_checks_total counter\ndnsgeeo_malicious_checks_total %d\n", atomic.LoadInt64(&m.maliciousChecksTotal))
+
+	for label, path := range map[string]string{"city": cityDBPath, "asn": asnDBPath} {
+		if path == "" {
+			continue
+		}
+		age := -1.0
+		if info, err := os.Stat(path); err == nil {
+			age = time.Since(info.ModTime()).Seconds()
+		}
+		if age < 0 {
+			continue
+		}
+		fmt.Fprintf(w, "# HELP dnsgeeo_maxmind_db_age_seconds Age of the MaxMind DB file on disk.\n# TYPE dnsgeeo_maxmind_db_age_seconds gauge\ndnsgeeo_maxmind_db_age_seconds{db=%q} %f\n", label, age)
+	}
+}
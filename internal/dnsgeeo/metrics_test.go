@@ -0,0 +1,61 @@
+package dnsgeeo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestObserveDNSQueryLabelsByTransport guards against a regression where
+// every query's latency/count landed under a single hardcoded transport
+// label regardless of which one actually answered.
+func TestObserveDNSQueryLabelsByTransport(t *testing.T) {
+	m := NewMetrics()
+	m.ObserveDNSQuery("tls", 5*time.Millisecond, "")
+	m.ObserveDNSQuery("udp", 50*time.Millisecond, "timeout")
+
+	var sb strings.Builder
+	m.WritePrometheus(&sb, "", "")
+	out := sb.String()
+
+	if !strings.Contains(out, `dnsgeeo_dns_queries_total{label="tls"} 1`) {
+		t.Errorf("expected a tls query counter, got:\n%s", out)
+	}
+	if !strings.Contains(out, `dnsgeeo_dns_queries_total{label="udp"} 1`) {
+		t.Errorf("expected a udp query counter, got:\n%s", out)
+	}
+	if !strings.Contains(out, `dnsgeeo_dns_errors_total{label="timeout"} 1`) {
+		t.Errorf("expected a timeout error counter, got:\n%s", out)
+	}
+}
+
+// TestDNSLatencyHistogramBucketsAreCumulative guards against the latency
+// histogram regressing into a flat counter: every bucket at or above an
+// observation's duration must include it, and +Inf must equal the total
+// observation count.
+func TestDNSLatencyHistogramBucketsAreCumulative(t *testing.T) {
+	h := newDNSLatencyHistogram()
+	h.observe(2 * time.Millisecond)
+	h.observe(2 * time.Second)
+
+	buckets, sum, count := h.snapshot()
+	if count != 2 {
+		t.Fatalf("count = %d, want 2", count)
+	}
+	if sum <= 0 {
+		t.Fatalf("sum = %f, want > 0", sum)
+	}
+	// The 0.001s bucket is below both observations.
+	if buckets[0] != 0 {
+		t.Errorf("0.001s bucket = %d, want 0", buckets[0])
+	}
+	// The 0.01s bucket should only include the 2ms observation.
+	if buckets[2] != 1 {
+		t.Errorf("0.01s bucket = %d, want 1 (0.01s index)", buckets[2])
+	}
+	// The 10s bucket (last) should include both observations.
+	last := len(buckets) - 1
+	if buckets[last] != 2 {
+		t.Errorf("10s bucket = %d, want 2", buckets[last])
+	}
+}
@@ -0,0 +1,81 @@
+package dnsgeeo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestFormatRRValueFormatsEachRecordType(t *testing.T) {
+	cases := []struct {
+		name string
+		rr   dns.RR
+		want string
+	}{
+		{"MX", &dns.MX{Preference: 10, Mx: "mail.example.com."}, "10 mail.example.com."},
+		{"TXT", &dns.TXT{Txt: []string{"v=spf1 ", "-all"}}, "v=spf1 -all"},
+		{"NS", &dns.NS{Ns: "ns1.example.com."}, "ns1.example.com."},
+		{"CAA", &dns.CAA{Flag: 0, Tag: "issue", Value: "letsencrypt.org"}, `0 issue "letsencrypt.org"`},
+		{"SRV", &dns.SRV{Priority: 10, Weight: 20, Port: 443, Target: "svc.example.com."}, "10 20 443 svc.example.com."},
+	}
+	for _, c := range cases {
+		if got := formatRRValue(c.rr); got != c.want {
+			t.Errorf("%s: formatRRValue = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+// recordStubTransport answers every query with a fixed set of resource
+// records, keyed by query type, so LookupRecords can be exercised without a
+// real network.
+type recordStubTransport struct {
+	answers map[uint16][]dns.RR
+}
+
+func (s *recordStubTransport) Exchange(_ context.Context, msg *dns.Msg) (*dns.Msg, error) {
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+	if len(msg.Question) > 0 {
+		resp.Answer = s.answers[msg.Question[0].Qtype]
+	}
+	return resp, nil
+}
+
+func (s *recordStubTransport) Descriptor() ServerDescriptor { return ServerDescriptor{Raw: "stub"} }
+
+// TestLookupRecordsGroupsByRequestedType guards the MX/TXT/NS/SOA/CAA/SRV
+// enrichment contract: each requested type name must come back grouped
+// under its own key, and unknown type names must be skipped rather than
+// erroring the whole call.
+func TestLookupRecordsGroupsByRequestedType(t *testing.T) {
+	stub := &recordStubTransport{answers: map[uint16][]dns.RR{
+		dns.TypeMX:  {&dns.MX{Preference: 10, Mx: "mail.example.com."}},
+		dns.TypeTXT: {&dns.TXT{Txt: []string{"v=spf1 -all"}}},
+	}}
+	r := &RRResolver{transports: []Transport{stub}}
+
+	got := r.LookupRecords(context.Background(), "example.com", []string{"MX", "TXT", "NOTAREALTYPE"})
+
+	if len(got["MX"]) != 1 || got["MX"][0] != "10 mail.example.com." {
+		t.Errorf("MX = %v", got["MX"])
+	}
+	if len(got["TXT"]) != 1 || got["TXT"][0] != "v=spf1 -all" {
+		t.Errorf("TXT = %v", got["TXT"])
+	}
+	if _, ok := got["NOTAREALTYPE"]; ok {
+		t.Error("expected an unknown type name to be silently skipped")
+	}
+}
+
+func TestLookupRecordsReturnsNilForNoTypesOrTransports(t *testing.T) {
+	r := &RRResolver{transports: []Transport{&recordStubTransport{}}}
+	if got := r.LookupRecords(context.Background(), "example.com", nil); got != nil {
+		t.Errorf("expected nil with no requested types, got %v", got)
+	}
+
+	empty := &RRResolver{}
+	if got := empty.LookupRecords(context.Background(), "example.com", []string{"MX"}); got != nil {
+		t.Errorf("expected nil with no transports, got %v", got)
+	}
+}
@@ -0,0 +1,185 @@
+package dnsgeeo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// geositeCategories is a minimal built-in substitute for the full v2ray
+// domain-list-community "geosite.dat", which is not vendored in this
+// build. Only the categories below are recognized; a "geosite:" route for
+// any other category simply never matches. Real geosite support would
+// load the community domain list at startup instead of hardcoding it.
+var geositeCategories = map[string][]string{
+	"cn":      {"qq.com", "baidu.com", "taobao.com", "weibo.com", "aliyun.com", "alipay.com", "bilibili.com"},
+	"private": {"localhost", "local", "internal", "lan"},
+}
+
+// routeMatcher decides whether a Route applies to a queried domain.
+type routeMatcher interface {
+	match(domain string) bool
+}
+
+// suffixMatcher implements "suffix:example.com": matches example.com and
+// any of its subdomains.
+type suffixMatcher string
+
+func (m suffixMatcher) match(domain string) bool {
+	suf := strings.ToLower(strings.TrimPrefix(string(m), "."))
+	domain = strings.ToLower(domain)
+	return domain == suf || strings.HasSuffix(domain, "."+suf)
+}
+
+// keywordMatcher implements "keyword:ads": matches any domain containing
+// the keyword as a substring.
+type keywordMatcher string
+
+func (m keywordMatcher) match(domain string) bool {
+	return strings.Contains(strings.ToLower(domain), strings.ToLower(string(m)))
+}
+
+// regexMatcher implements "regex:...".
+type regexMatcher struct{ re *regexp.Regexp }
+
+func (m regexMatcher) match(domain string) bool { return m.re.MatchString(domain) }
+
+// geositeMatcher implements "geosite:cn" against geositeCategories.
+type geositeMatcher string
+
+func (m geositeMatcher) match(domain string) bool {
+	domain = strings.ToLower(domain)
+	for _, suf := range geositeCategories[strings.ToLower(string(m))] {
+		if domain == suf || strings.HasSuffix(domain, "."+suf) {
+			return true
+		}
+	}
+	return false
+}
+
+// compiledRoute is a Route with its Match field parsed into a routeMatcher.
+type compiledRoute struct {
+	matcher routeMatcher
+	group   string
+	raw     string // original Match string, recorded on HostResult.Route
+}
+
+// parseRoute compiles a single Route's Match field ("suffix:...",
+// "keyword:...", "regex:...", or "geosite:...") into a compiledRoute.
+func parseRoute(r Route) (compiledRoute, error) {
+	idx := strings.IndexByte(r.Match, ':')
+	if idx < 0 {
+		return compiledRoute{}, fmt.Errorf("invalid route match %q: want kind:value", r.Match)
+	}
+	kind, value := r.Match[:idx], r.Match[idx+1:]
+
+	var m routeMatcher
+	switch kind {
+	case "suffix":
+		m = suffixMatcher(value)
+	case "keyword":
+		m = keywordMatcher(value)
+	case "regex":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return compiledRoute{}, fmt.Errorf("invalid route regex %q: %w", value, err)
+		}
+		m = regexMatcher{re: re}
+	case "geosite":
+		m = geositeMatcher(value)
+	default:
+		return compiledRoute{}, fmt.Errorf("invalid route match %q: unknown kind %q", r.Match, kind)
+	}
+	if r.Group == "" {
+		return compiledRoute{}, fmt.Errorf("invalid route %q: missing group", r.Match)
+	}
+
+	return compiledRoute{matcher: m, group: r.Group, raw: r.Match}, nil
+}
+
+// defaultGroupName is the implicit upstream group a domain dispatches to
+// when no route matches.
+const defaultGroupName = "default"
+
+// Router dispatches a queried domain to the RRResolver for its matching
+// upstream group (Config.Routes/Config.UpstreamGroups), falling back to
+// the default group when no route matches or a matched group is unknown.
+type Router struct {
+	routes    []compiledRoute
+	resolvers map[string]*RRResolver
+}
+
+// NewRouter compiles cfg.Routes and builds one RRResolver per entry in
+// cfg.UpstreamGroups, reusing defaultResolver (built from cfg.DNSServers)
+// as the implicit "default" group so every group shares the same
+// transport/bootstrap settings.
+func NewRouter(cfg *Config, defaultResolver *RRResolver) (*Router, error) {
+	routes := make([]compiledRoute, 0, len(cfg.Routes))
+	for _, r := range cfg.Routes {
+		cr, err := parseRoute(r)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, cr)
+	}
+
+	resolvers := map[string]*RRResolver{defaultGroupName: defaultResolver}
+	for name, servers := range cfg.UpstreamGroups {
+		res, err := NewRRResolverWithConfig(servers, cfg.Transport, cfg.BootstrapServers)
+		if err != nil {
+			return nil, fmt.Errorf("upstream group %q: %w", name, err)
+		}
+		resolvers[name] = res
+	}
+
+	return &Router{routes: routes, resolvers: resolvers}, nil
+}
+
+// Route returns the resolver for domain's matching upstream group: the
+// group named by the first matching rule, or the default group if no rule
+// matches (or the matched rule names an unknown group). rule is the
+// original Match string of whichever rule matched, or "" for the default.
+func (rt *Router) Route(domain string) (resolver *RRResolver, group string, rule string) {
+	for _, cr := range rt.routes {
+		if !cr.matcher.match(domain) {
+			continue
+		}
+		if res, ok := rt.resolvers[cr.group]; ok {
+			return res, cr.group, cr.raw
+		}
+		break
+	}
+	return rt.resolvers[defaultGroupName], defaultGroupName, ""
+}
+
+// activeRouter is the process-wide rule table used by
+// ResolveAndEnrichBatch/StreamResolveAndEnrichBatch/EnumerateBatch. It is
+// nil until InitRouter is called with a non-empty Config.Routes; while it
+// is nil, routing is a no-op (every domain uses the caller's resolver),
+// matching the pre-routing behavior for existing callers.
+var activeRouter *Router
+
+// InitRouter compiles cfg.Routes and builds cfg.UpstreamGroups into a
+// process-wide Router, using defaultResolver as the "default" group. A
+// no-op when cfg.Routes is empty. Mirrors InitCache/InitWhois/InitBlocklists.
+func InitRouter(cfg *Config, defaultResolver *RRResolver) error {
+	if len(cfg.Routes) == 0 {
+		return nil
+	}
+	router, err := NewRouter(cfg, defaultResolver)
+	if err != nil {
+		return err
+	}
+	activeRouter = router
+	return nil
+}
+
+// routeFor returns the resolver, upstream group name, and matched rule
+// (all via activeRouter) for domain. When routing hasn't been initialized
+// it returns r with an empty group and rule, so HostResult omits both.
+func routeFor(r *RRResolver, domain string) (resolver *RRResolver, group string, rule string) {
+	if activeRouter == nil {
+		return r, "", ""
+	}
+	return activeRouter.Route(domain)
+}
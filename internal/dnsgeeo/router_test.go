@@ -0,0 +1,78 @@
+package dnsgeeo
+
+import "testing"
+
+func TestParseRouteMatchKinds(t *testing.T) {
+	cases := []struct {
+		match   string
+		domain  string
+		matches bool
+	}{
+		{"suffix:example.com", "www.example.com", true},
+		{"suffix:example.com", "notexample.com", false},
+		{"keyword:ads", "ads.tracker.net", true},
+		{"keyword:ads", "clean.example.com", false},
+		{"regex:^api\\.", "api.example.com", true},
+		{"regex:^api\\.", "example.com", false},
+		{"geosite:cn", "www.baidu.com", true},
+		{"geosite:cn", "www.example.com", false},
+	}
+	for _, c := range cases {
+		cr, err := parseRoute(Route{Match: c.match, Group: "g"})
+		if err != nil {
+			t.Fatalf("parseRoute(%q): %v", c.match, err)
+		}
+		if got := cr.matcher.match(c.domain); got != c.matches {
+			t.Errorf("parseRoute(%q).match(%q) = %v, want %v", c.match, c.domain, got, c.matches)
+		}
+	}
+}
+
+func TestParseRouteRejectsMalformedMatch(t *testing.T) {
+	if _, err := parseRoute(Route{Match: "nocolon", Group: "g"}); err == nil {
+		t.Error("expected an error for a Match with no kind:value separator")
+	}
+	if _, err := parseRoute(Route{Match: "bogus:value", Group: "g"}); err == nil {
+		t.Error("expected an error for an unknown match kind")
+	}
+	if _, err := parseRoute(Route{Match: "suffix:example.com", Group: ""}); err == nil {
+		t.Error("expected an error for a route with no group")
+	}
+	if _, err := parseRoute(Route{Match: "regex:(", Group: "g"}); err == nil {
+		t.Error("expected an error for an invalid regex")
+	}
+}
+
+// TestRouterRouteFallsBackToDefault guards the dispatch fallback contract:
+// a domain matching no route, or matching a route whose group was never
+// registered, must land on the "default" group rather than erroring.
+func TestRouterRouteFallsBackToDefault(t *testing.T) {
+	defaultResolver := &RRResolver{}
+	rt := &Router{
+		routes:    []compiledRoute{{matcher: suffixMatcher("ads.example.com"), group: "missing-group", raw: "suffix:ads.example.com"}},
+		resolvers: map[string]*RRResolver{defaultGroupName: defaultResolver},
+	}
+
+	res, group, rule := rt.Route("unrelated.example.com")
+	if res != defaultResolver || group != defaultGroupName || rule != "" {
+		t.Errorf("no-match route = (%v, %q, %q), want default resolver", res, group, rule)
+	}
+
+	res, group, rule = rt.Route("sub.ads.example.com")
+	if res != defaultResolver || group != defaultGroupName {
+		t.Errorf("unregistered-group match = (%v, %q, %q), want fallback to default", res, group, rule)
+	}
+}
+
+func TestRouterRouteDispatchesToMatchedGroup(t *testing.T) {
+	corpResolver := &RRResolver{}
+	rt := &Router{
+		routes:    []compiledRoute{{matcher: suffixMatcher("corp.example.com"), group: "corp", raw: "suffix:corp.example.com"}},
+		resolvers: map[string]*RRResolver{defaultGroupName: &RRResolver{}, "corp": corpResolver},
+	}
+
+	res, group, rule := rt.Route("vpn.corp.example.com")
+	if res != corpResolver || group != "corp" || rule != "suffix:corp.example.com" {
+		t.Errorf("Route = (%v, %q, %q), want corp resolver", res, group, rule)
+	}
+}
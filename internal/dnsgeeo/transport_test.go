@@ -0,0 +1,163 @@
+package dnsgeeo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestParseServerDescriptorSchemes(t *testing.T) {
+	d, err := ParseServerDescriptor("tls://1.1.1.1:853")
+	if err != nil {
+		t.Fatalf("ParseServerDescriptor: %v", err)
+	}
+	if d.Scheme != SchemeDoT || d.Host != "1.1.1.1" || d.Port != "853" {
+		t.Fatalf("got %+v", d)
+	}
+}
+
+// TestParseServerDescriptorRejectsMalformedDNSStamp guards against a
+// regression where decodeDNSStamp was never called, so any string after
+// "sdns://" was accepted as a valid DNSCrypt descriptor.
+func TestParseServerDescriptorRejectsMalformedDNSStamp(t *testing.T) {
+	if _, err := ParseServerDescriptor("sdns://not-valid-base64url!!!"); err == nil {
+		t.Fatal("expected an error for a malformed sdns:// stamp")
+	}
+}
+
+func TestParseServerDescriptorAcceptsValidDNSStamp(t *testing.T) {
+	d, err := ParseServerDescriptor("sdns://AQcAAAAAAAAABzkuOS45Ljk")
+	if err != nil {
+		t.Fatalf("ParseServerDescriptor: %v", err)
+	}
+	if d.Scheme != SchemeDNSCrypt {
+		t.Fatalf("Scheme = %v, want %v", d.Scheme, SchemeDNSCrypt)
+	}
+}
+
+func TestParseServerDescriptorSchemeVariants(t *testing.T) {
+	cases := []struct {
+		raw        string
+		scheme     TransportScheme
+		host, port string
+		path       string
+	}{
+		{"8.8.8.8:53", SchemeUDP, "8.8.8.8", "53", ""},
+		{"8.8.8.8", SchemeUDP, "8.8.8.8", "53", ""}, // no port -> default 53
+		{"tcp://8.8.8.8:53", SchemeTCP, "8.8.8.8", "53", ""},
+		{"tcp://8.8.8.8", SchemeTCP, "8.8.8.8", "53", ""}, // default TCP port
+		{"https://dns.google/dns-query", SchemeDoH, "dns.google", "443", "/dns-query"},
+		{"https://dns.google", SchemeDoH, "dns.google", "443", "/dns-query"}, // default path
+	}
+	for _, c := range cases {
+		d, err := ParseServerDescriptor(c.raw)
+		if err != nil {
+			t.Fatalf("ParseServerDescriptor(%q): %v", c.raw, err)
+		}
+		if d.Scheme != c.scheme || d.Host != c.host || d.Port != c.port {
+			t.Errorf("ParseServerDescriptor(%q) = %+v, want scheme=%v host=%v port=%v", c.raw, d, c.scheme, c.host, c.port)
+		}
+		if c.path != "" && d.Path != c.path {
+			t.Errorf("ParseServerDescriptor(%q).Path = %q, want %q", c.raw, d.Path, c.path)
+		}
+	}
+}
+
+func TestParseServerDescriptorRejectsUnsupportedScheme(t *testing.T) {
+	if _, err := ParseServerDescriptor("ftp://example.com"); err == nil {
+		t.Fatal("expected an error for an unsupported scheme")
+	}
+}
+
+func TestNewTransportBuildsExpectedImplementation(t *testing.T) {
+	udp, err := ParseServerDescriptor("8.8.8.8:53")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := NewTransport(udp, TransportConfig{})
+	if err != nil {
+		t.Fatalf("NewTransport(udp): %v", err)
+	}
+	if _, ok := tr.(*dnsClientTransport); !ok {
+		t.Errorf("NewTransport(udp) = %T, want *dnsClientTransport", tr)
+	}
+
+	doh, err := ParseServerDescriptor("https://dns.google/dns-query")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err = NewTransport(doh, TransportConfig{})
+	if err != nil {
+		t.Fatalf("NewTransport(doh): %v", err)
+	}
+	if _, ok := tr.(*dohTransport); !ok {
+		t.Errorf("NewTransport(doh) = %T, want *dohTransport", tr)
+	}
+}
+
+// stubTransport is a fake Transport for exercising RRResolver's round-robin
+// dispatch without a real network.
+type stubTransport struct {
+	desc ServerDescriptor
+}
+
+func (s *stubTransport) Exchange(_ context.Context, msg *dns.Msg) (*dns.Msg, error) {
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+	return resp, nil
+}
+
+func (s *stubTransport) Descriptor() ServerDescriptor { return s.desc }
+
+// TestRRResolverLookupIPAddrRoundRobinsAcrossTransports guards the resolver's
+// core dispatch contract: successive LookupIPAddr calls must cycle through
+// every configured upstream rather than always hitting the first one.
+func TestRRResolverLookupIPAddrRoundRobinsAcrossTransports(t *testing.T) {
+	r := &RRResolver{transports: []Transport{
+		&stubTransport{desc: ServerDescriptor{Raw: "1.1.1.1:53"}},
+		&stubTransport{desc: ServerDescriptor{Raw: "8.8.8.8:53"}},
+	}}
+
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		_, usedServer, _, err := r.LookupIPAddr(context.Background(), "example.com")
+		if err != nil {
+			t.Fatalf("LookupIPAddr: %v", err)
+		}
+		seen[usedServer] = true
+	}
+	if !seen["1.1.1.1:53"] || !seen["8.8.8.8:53"] {
+		t.Errorf("expected both upstreams to be used across 2 calls, got %v", seen)
+	}
+}
+
+// TestNewTransportDoQAndDNSCryptAreUnsupported guards the honest-failure
+// contract for schemes whose crypto stacks are not vendored in this build:
+// they must parse and construct without error, but every Exchange call
+// must fail loudly rather than silently falling back to another transport.
+func TestNewTransportDoQAndDNSCryptAreUnsupported(t *testing.T) {
+	doq, err := ParseServerDescriptor("quic://dns.example.com:853")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := NewTransport(doq, TransportConfig{})
+	if err != nil {
+		t.Fatalf("NewTransport(doq): %v", err)
+	}
+	if _, err := tr.Exchange(context.Background(), new(dns.Msg)); err == nil {
+		t.Error("expected Exchange on an unsupported DoQ transport to fail")
+	}
+
+	crypt, err := ParseServerDescriptor("sdns://AQcAAAAAAAAABzkuOS45Ljk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err = NewTransport(crypt, TransportConfig{})
+	if err != nil {
+		t.Fatalf("NewTransport(dnscrypt): %v", err)
+	}
+	if _, err := tr.Exchange(context.Background(), new(dns.Msg)); err == nil {
+		t.Error("expected Exchange on an unsupported DNSCrypt transport to fail")
+	}
+}
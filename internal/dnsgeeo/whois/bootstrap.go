@@ -0,0 +1,177 @@
+// Package whois is a native Go replacement for the whois_rdap.py helper. It
+// implements an RDAP client that follows the IANA bootstrap registries, a
+// WHOIS/43 fallback client that chases registrar referrals, and a native
+// Public Suffix List loader, so domain/IP provenance lookups no longer
+// require shelling out to Python.
+package whois
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	bootstrapDNSURL  = "https://data.iana.org/rdap/dns.json"
+	bootstrapASNURL  = "https://data.iana.org/rdap/asn.json"
+	bootstrapIPv4URL = "https://data.iana.org/rdap/ipv4.json"
+	bootstrapIPv6URL = "https://data.iana.org/rdap/ipv6.json"
+)
+
+// bootstrapRegistry mirrors the shape of IANA's RDAP bootstrap files:
+// {"services": [[["com","net"], ["https://rdap.verisign.com/com/v1/"]], ...]}
+type bootstrapRegistry struct {
+	Services [][][]string `json:"services"`
+}
+
+// bootstrapCache fetches and caches one of the IANA bootstrap registries on
+// disk, revalidating with ETag/Last-Modified so repeated runs don't re-fetch
+// unchanged files.
+type bootstrapCache struct {
+	dir        string
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	byKey map[string]*bootstrapRegistry
+}
+
+func newBootstrapCache(cacheDir string, client *http.Client) *bootstrapCache {
+	return &bootstrapCache{dir: cacheDir, httpClient: client, byKey: map[string]*bootstrapRegistry{}}
+}
+
+// get returns the parsed registry for url, fetching it (and refreshing the
+// on-disk copy) if needed.
+func (b *bootstrapCache) get(ctx context.Context, url string) (*bootstrapRegistry, error) {
+	b.mu.Lock()
+	if reg, ok := b.byKey[url]; ok {
+		b.mu.Unlock()
+		return reg, nil
+	}
+	b.mu.Unlock()
+
+	reg, err := b.load(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	b.byKey[url] = reg
+	b.mu.Unlock()
+	return reg, nil
+}
+
+func (b *bootstrapCache) cachePaths(url string) (dataPath, metaPath string) {
+	name := strings.NewReplacer("/", "_", ":", "_").Replace(url)
+	return filepath.Join(b.dir, name+".json"), filepath.Join(b.dir, name+".meta")
+}
+
+func (b *bootstrapCache) load(ctx context.Context, url string) (*bootstrapRegistry, error) {
+	dataPath, metaPath := b.cachePaths(url)
+	lastModPath := strings.TrimSuffix(metaPath, ".meta") + ".lastmod"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build bootstrap request for %s: %w", url, err)
+	}
+	if meta, err := os.ReadFile(metaPath); err == nil {
+		if etag := strings.TrimSpace(string(meta)); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+	}
+	if lastMod, err := os.ReadFile(lastModPath); err == nil {
+		if lm := strings.TrimSpace(string(lastMod)); lm != "" {
+			req.Header.Set("If-Modified-Since", lm)
+		}
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		if cached, cerr := readCachedRegistry(dataPath); cerr == nil {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("fetch bootstrap registry %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		if cached, cerr := readCachedRegistry(dataPath); cerr == nil {
+			return cached, nil
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if cached, cerr := readCachedRegistry(dataPath); cerr == nil {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("fetch bootstrap registry %s: unexpected status %d", url, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 8*1024*1024))
+	if err != nil {
+		return nil, fmt.Errorf("read bootstrap registry %s: %w", url, err)
+	}
+
+	var reg bootstrapRegistry
+	if err := json.Unmarshal(body, &reg); err != nil {
+		return nil, fmt.Errorf("parse bootstrap registry %s: %w", url, err)
+	}
+
+	if b.dir != "" {
+		if err := os.MkdirAll(b.dir, 0o755); err == nil {
+			_ = os.WriteFile(dataPath, body, 0o644)
+			if etag := resp.Header.Get("ETag"); etag != "" {
+				_ = os.WriteFile(metaPath, []byte(etag), 0o644)
+			}
+			if lm := resp.Header.Get("Last-Modified"); lm != "" {
+				_ = os.WriteFile(lastModPath, []byte(lm), 0o644)
+			}
+		}
+	}
+
+	return &reg, nil
+}
+
+func readCachedRegistry(path string) (*bootstrapRegistry, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var reg bootstrapRegistry
+	if err := json.Unmarshal(body, &reg); err != nil {
+		return nil, err
+	}
+	return &reg, nil
+}
+
+// serversFor returns the RDAP base URLs whose entry key matches label
+// (a TLD, ASN range start, or IP prefix depending on the registry).
+func (r *bootstrapRegistry) serversFor(label string) []string {
+	label = strings.ToLower(label)
+	for _, entry := range r.Services {
+		if len(entry) < 2 {
+			continue
+		}
+		for _, key := range entry[0] {
+			if strings.ToLower(key) == label {
+				return entry[1]
+			}
+		}
+	}
+	return nil
+}
+
+func defaultCacheDir() string {
+	if dir, err := os.UserCacheDir(); err == nil && dir != "" {
+		return filepath.Join(dir, "dnsgeeo", "rdap-bootstrap")
+	}
+	return filepath.Join(os.TempDir(), "dnsgeeo-rdap-bootstrap")
+}
+
+var defaultHTTPClient = &http.Client{Timeout: 15 * time.Second}
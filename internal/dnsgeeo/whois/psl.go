@@ -0,0 +1,280 @@
+package whois
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const pslURL = "https://publicsuffix.org/list/public_suffix_list.dat"
+
+// PSLPrivateEntry is one entry from the PRIVATE section of the Public
+// Suffix List, along with the comment line (if any) naming its owner.
+type PSLPrivateEntry struct {
+	Suffix string `json:"suffix"`
+	Owner  string `json:"owner,omitempty"`
+}
+
+// PSL is a loaded Public Suffix List, split into its ICANN and PRIVATE
+// sections so callers can answer both "public suffix" and "this belongs to
+// a dynamic DNS / hosting provider" style queries without exec'ing Python.
+type PSL struct {
+	icann             map[string]bool // literal rules, e.g. "co.uk"
+	icannEx           map[string]bool // "!" exception rules, stored without the "!"
+	icannWildcard     map[string]bool // base of a "*.base" rule, e.g. "ck" for "*.ck"
+	priv              map[string]bool
+	privEx            map[string]bool
+	privWildcard      map[string]bool
+	privOwnerBySuffix map[string]string
+
+	mu sync.RWMutex
+}
+
+// LoadPSL fetches the Mozilla Public Suffix List and parses it into a PSL.
+// A successful fetch is written to cachePath (if set); the cached copy is
+// read only when the fetch fails or returns a non-200 status.
+func LoadPSL(ctx context.Context, cachePath string) (*PSL, error) {
+	body, err := fetchPSL(ctx, cachePath)
+	if err != nil {
+		return nil, err
+	}
+	return parsePSL(body), nil
+}
+
+func fetchPSL(ctx context.Context, cachePath string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pslURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build PSL request: %w", err)
+	}
+
+	resp, err := defaultHTTPClient.Do(req)
+	if err != nil {
+		if cached, cerr := os.ReadFile(cachePath); cerr == nil && cachePath != "" {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("fetch public suffix list: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		if cached, cerr := os.ReadFile(cachePath); cerr == nil && cachePath != "" {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("fetch public suffix list: unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 8*1024*1024))
+	if err != nil {
+		return nil, fmt.Errorf("read public suffix list: %w", err)
+	}
+
+	if cachePath != "" {
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err == nil {
+			_ = os.WriteFile(cachePath, body, 0o644)
+		}
+	}
+
+	return body, nil
+}
+
+func parsePSL(body []byte) *PSL {
+	p := &PSL{
+		icann:             map[string]bool{},
+		icannEx:           map[string]bool{},
+		icannWildcard:     map[string]bool{},
+		priv:              map[string]bool{},
+		privEx:            map[string]bool{},
+		privWildcard:      map[string]bool{},
+		privOwnerBySuffix: map[string]string{},
+	}
+
+	section := ""
+	pendingOwner := ""
+	scanner := bufio.NewScanner(strings.NewReader(string(body)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			pendingOwner = ""
+			continue
+		case strings.HasPrefix(line, "// ===BEGIN ICANN DOMAINS==="):
+			section = "icann"
+			continue
+		case strings.HasPrefix(line, "// ===END ICANN DOMAINS==="):
+			section = ""
+			continue
+		case strings.HasPrefix(line, "// ===BEGIN PRIVATE DOMAINS==="):
+			section = "private"
+			continue
+		case strings.HasPrefix(line, "// ===END PRIVATE DOMAINS==="):
+			section = ""
+			continue
+		case strings.HasPrefix(line, "//"):
+			// Comment lines in the PRIVATE section conventionally name the
+			// submitting organization for the rules that follow.
+			pendingOwner = strings.TrimSpace(strings.TrimPrefix(line, "//"))
+			continue
+		}
+
+		negate := strings.HasPrefix(line, "!")
+		rule := strings.TrimPrefix(line, "!")
+		wildcard := strings.HasPrefix(rule, "*.")
+		rule = strings.TrimPrefix(rule, "*.")
+
+		switch section {
+		case "icann":
+			switch {
+			case negate:
+				p.icannEx[rule] = true
+			case wildcard:
+				p.icannWildcard[rule] = true
+			default:
+				p.icann[rule] = true
+			}
+		case "private":
+			switch {
+			case negate:
+				p.privEx[rule] = true
+			case wildcard:
+				p.privWildcard[rule] = true
+				if pendingOwner != "" {
+					p.privOwnerBySuffix[rule] = pendingOwner
+				}
+			default:
+				p.priv[rule] = true
+				if pendingOwner != "" {
+					p.privOwnerBySuffix[rule] = pendingOwner
+				}
+			}
+		}
+	}
+	return p
+}
+
+// PublicSuffix returns the longest matching ICANN public suffix of domain,
+// or "" if none matches (domain is itself a top-level suffix candidate).
+func (p *PSL) PublicSuffix(domain string) string {
+	suffix, _ := p.longestMatch(domain, p.icann, p.icannEx, p.icannWildcard)
+	return suffix
+}
+
+// PrivateSuffix returns the longest matching PRIVATE-section suffix of
+// domain (e.g. "github.io"), or "" if domain has none.
+func (p *PSL) PrivateSuffix(domain string) string {
+	suffix, _ := p.longestMatch(domain, p.priv, p.privEx, p.privWildcard)
+	return suffix
+}
+
+// PrivateOwner returns the organization that submitted the PRIVATE rule
+// matching domain's private suffix, if known.
+func (p *PSL) PrivateOwner(domain string) string {
+	_, ruleKey := p.longestMatch(domain, p.priv, p.privEx, p.privWildcard)
+	if ruleKey == "" {
+		return ""
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.privOwnerBySuffix[ruleKey]
+}
+
+// RegistrableDomain returns the public (ICANN) registrable domain for
+// domain, i.e. the public suffix plus one label, or "" if domain is not
+// long enough to have one.
+func (p *PSL) RegistrableDomain(domain string) string {
+	return registrableDomain(domain, p.PublicSuffix(domain))
+}
+
+// PrivateRegistrableDomain returns the registrable domain against the
+// PRIVATE section (falling back to the ICANN section when no private rule
+// matches), e.g. "foo.github.io" -> "foo.github.io" since github.io itself
+// is the private suffix.
+func (p *PSL) PrivateRegistrableDomain(domain string) string {
+	if suffix := p.PrivateSuffix(domain); suffix != "" {
+		return registrableDomain(domain, suffix)
+	}
+	return p.RegistrableDomain(domain)
+}
+
+// IsPrivate reports whether domain falls under a PRIVATE section suffix.
+func (p *PSL) IsPrivate(domain string) bool {
+	return p.PrivateSuffix(domain) != ""
+}
+
+// PrivateEntries returns every literal PRIVATE-section suffix rule along
+// with its owner comment, mirroring the old whois_rdap.py
+// `--psl-private-list` mode. "*." wildcard and "!" exception rules are not
+// included. The order of the entries is not defined.
+func (p *PSL) PrivateEntries() []PSLPrivateEntry {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	out := make([]PSLPrivateEntry, 0, len(p.priv))
+	for suffix := range p.priv {
+		out = append(out, PSLPrivateEntry{Suffix: suffix, Owner: p.privOwnerBySuffix[suffix]})
+	}
+	return out
+}
+
+// longestMatch returns the longest matching suffix of domain against
+// rules/exceptions/wildcards, along with the raw rule key that produced
+// it (the literal matched text for an exact rule, or the wildcard's base
+// for a "*.base" rule — e.g. "ck" for the "*.ck" rule that resolves
+// "foo.ck" as the suffix of "bar.foo.ck"). Callers that only need the
+// suffix (PublicSuffix/PrivateSuffix) can discard the key; PrivateOwner
+// uses it to look up privOwnerBySuffix, which is keyed the same way.
+func (p *PSL) longestMatch(domain string, rules, exceptions, wildcards map[string]bool) (suffix string, ruleKey string) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	labels := strings.Split(domain, ".")
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for i := range labels {
+		candidate := strings.Join(labels[i:], ".")
+		if exceptions[candidate] {
+			// An exception rule means everything below the next label down
+			// is public, i.e. the suffix is the candidate minus its first label.
+			if len(labels) > i+1 {
+				return strings.Join(labels[i+1:], "."), candidate
+			}
+			continue
+		}
+		if rules[candidate] && len(candidate) > len(suffix) {
+			suffix, ruleKey = candidate, candidate
+		}
+		// A wildcard rule ("*.ck") makes any direct child of candidate a
+		// public suffix in its own right, one label longer than candidate
+		// itself — so "*.ck" matching "ck" resolves to "foo.ck", not "ck".
+		if wildcards[candidate] && i > 0 {
+			resolved := strings.Join(labels[i-1:], ".")
+			if len(resolved) > len(suffix) {
+				suffix, ruleKey = resolved, candidate
+			}
+		}
+	}
+	return suffix, ruleKey
+}
+
+func registrableDomain(domain, suffix string) string {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	if suffix == "" || !strings.HasSuffix(domain, suffix) {
+		return ""
+	}
+	rest := strings.TrimSuffix(domain, suffix)
+	rest = strings.TrimSuffix(rest, ".")
+	if rest == "" {
+		return ""
+	}
+	idx := strings.LastIndex(rest, ".")
+	label := rest
+	if idx != -1 {
+		label = rest[idx+1:]
+	}
+	return label + "." + suffix
+}
@@ -0,0 +1,68 @@
+package whois
+
+import "testing"
+
+const testPSLData = `// ===BEGIN ICANN DOMAINS===
+com
+co.uk
+*.ck
+!www.ck
+
+// ===END ICANN DOMAINS===
+// ===BEGIN PRIVATE DOMAINS===
+// Example Co
+github.io
+*.platform.example
+// ===END PRIVATE DOMAINS===
+`
+
+// TestPublicSuffixWildcardRule guards against a regression where "*.ck"-style
+// wildcard rules were stored stripped of their "*." prefix in the same flat
+// map as literal rules, collapsing "bar.foo.ck" to the suffix "ck" instead
+// of the correct one-extra-label "foo.ck".
+func TestPublicSuffixWildcardRule(t *testing.T) {
+	p := parsePSL([]byte(testPSLData))
+
+	if got := p.PublicSuffix("bar.foo.ck"); got != "foo.ck" {
+		t.Fatalf("PublicSuffix(bar.foo.ck) = %q, want %q", got, "foo.ck")
+	}
+	if got := p.PublicSuffix("example.com"); got != "com" {
+		t.Fatalf("PublicSuffix(example.com) = %q, want %q", got, "com")
+	}
+	if got := p.PublicSuffix("example.co.uk"); got != "co.uk" {
+		t.Fatalf("PublicSuffix(example.co.uk) = %q, want %q", got, "co.uk")
+	}
+}
+
+func TestPublicSuffixException(t *testing.T) {
+	p := parsePSL([]byte(testPSLData))
+
+	if got := p.PublicSuffix("www.ck"); got != "ck" {
+		t.Fatalf("PublicSuffix(www.ck) = %q, want %q", got, "ck")
+	}
+}
+
+func TestPrivateSuffixWildcardAndOwner(t *testing.T) {
+	p := parsePSL([]byte(testPSLData))
+
+	if got := p.PrivateSuffix("foo.bar.platform.example"); got != "bar.platform.example" {
+		t.Fatalf("PrivateSuffix(foo.bar.platform.example) = %q, want %q", got, "bar.platform.example")
+	}
+	if got := p.PrivateSuffix("myapp.github.io"); got != "github.io" {
+		t.Fatalf("PrivateSuffix(myapp.github.io) = %q, want %q", got, "github.io")
+	}
+	if got := p.PrivateOwner("myapp.github.io"); got != "Example Co" {
+		t.Fatalf("PrivateOwner(myapp.github.io) = %q, want %q", got, "Example Co")
+	}
+}
+
+func TestRegistrableDomain(t *testing.T) {
+	p := parsePSL([]byte(testPSLData))
+
+	if got := p.RegistrableDomain("www.example.com"); got != "example.com" {
+		t.Fatalf("RegistrableDomain(www.example.com) = %q, want %q", got, "example.com")
+	}
+	if got := p.RegistrableDomain("bar.foo.ck"); got != "bar.foo.ck" {
+		t.Fatalf("RegistrableDomain(bar.foo.ck) = %q, want %q", got, "bar.foo.ck")
+	}
+}
@@ -0,0 +1,301 @@
+package whois
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// rdapEvent mirrors the "events" array of an RDAP domain/entity response.
+type rdapEvent struct {
+	Action string `json:"eventAction"`
+	Date   string `json:"eventDate"`
+}
+
+type rdapVCardEntry struct {
+	// vCardArray is ["vcard", [[field, params, type, value], ...]]; we only
+	// need a handful of fields, so decode loosely into [][]any.
+	raw [][]any
+}
+
+func (v *rdapVCardEntry) UnmarshalJSON(b []byte) error {
+	var arr []json.RawMessage
+	if err := json.Unmarshal(b, &arr); err != nil || len(arr) != 2 {
+		return nil
+	}
+	var fields [][]any
+	if err := json.Unmarshal(arr[1], &fields); err != nil {
+		return nil
+	}
+	v.raw = fields
+	return nil
+}
+
+func (v *rdapVCardEntry) value(field string) string {
+	for _, f := range v.raw {
+		if len(f) < 4 {
+			continue
+		}
+		name, _ := f[0].(string)
+		if !strings.EqualFold(name, field) {
+			continue
+		}
+		return vcardValueToString(f[3])
+	}
+	return ""
+}
+
+// country returns the country component of a structured vCard field (e.g.
+// the last of "adr"'s 7 components), or "" if field is absent or not a
+// structured array.
+func (v *rdapVCardEntry) country(field string) string {
+	for _, f := range v.raw {
+		if len(f) < 4 {
+			continue
+		}
+		name, _ := f[0].(string)
+		if !strings.EqualFold(name, field) {
+			continue
+		}
+		arr, ok := f[3].([]any)
+		if !ok || len(arr) == 0 {
+			return ""
+		}
+		country, _ := arr[len(arr)-1].(string)
+		return country
+	}
+	return ""
+}
+
+// vcardValueToString renders a jCard property value (RFC 7095) as a
+// string. Most properties are a plain string, but structured ones like
+// "adr" (a 7-component address) or a multi-valued "org" encode their
+// value as a JSON array instead, recursively in the case of a
+// multi-line street address; this flattens either shape into one
+// comma-separated string rather than silently returning "" for anything
+// that isn't a bare string.
+func vcardValueToString(raw any) string {
+	switch v := raw.(type) {
+	case string:
+		return v
+	case []any:
+		var parts []string
+		for _, item := range v {
+			if s := vcardValueToString(item); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return ""
+	}
+}
+
+type rdapEntity struct {
+	Roles    []string       `json:"roles"`
+	VCard    rdapVCardEntry `json:"vcardArray"`
+	Entities []rdapEntity   `json:"entities"`
+}
+
+type rdapDomain struct {
+	LDHName     string      `json:"ldhName"`
+	Status      []string    `json:"status"`
+	Events      []rdapEvent `json:"events"`
+	Nameservers []struct {
+		LDHName string `json:"ldhName"`
+	} `json:"nameservers"`
+	Entities []rdapEntity `json:"entities"`
+}
+
+// RDAPResult is the subset of an RDAP domain response this package surfaces.
+type RDAPResult struct {
+	URL              string
+	CreatedAt        string
+	Status           []string
+	Events           []RDAPEvent
+	NameServers      []string
+	Registrar        string
+	RegistrarCountry string
+	RegistrantOrg    string
+	RegistrantAddr   string
+}
+
+// RDAPEvent is a single RDAP lifecycle event (registration, expiration,...).
+type RDAPEvent struct {
+	Action string
+	Date   string
+}
+
+// Client performs RDAP and WHOIS/43 lookups, backed by the IANA bootstrap
+// registries and a small set of per-TLD referral quirks.
+type Client struct {
+	httpClient *http.Client
+	bootstrap  *bootstrapCache
+}
+
+// NewClient returns a Client that caches bootstrap registries under
+// cacheDir (the OS user cache directory if cacheDir is empty).
+func NewClient(cacheDir string) *Client {
+	if cacheDir == "" {
+		cacheDir = defaultCacheDir()
+	}
+	return &Client{
+		httpClient: defaultHTTPClient,
+		bootstrap:  newBootstrapCache(cacheDir, defaultHTTPClient),
+	}
+}
+
+// LookupDomain queries RDAP for domain, following the IANA DNS bootstrap
+// registry to find the authoritative RDAP server and then any "related"
+// referral links the registry returns (e.g. thin-registry TLDs that refer
+// to the registrar's own RDAP server).
+func (c *Client) LookupDomain(ctx context.Context, domain string) (*RDAPResult, error) {
+	reg, err := c.bootstrap.get(ctx, bootstrapDNSURL)
+	if err != nil {
+		return nil, fmt.Errorf("load RDAP DNS bootstrap: %w", err)
+	}
+
+	tld := tldOf(domain)
+	bases := reg.serversFor(tld)
+	if len(bases) == 0 {
+		return nil, fmt.Errorf("no RDAP server known for .%s", tld)
+	}
+
+	var lastErr error
+	for _, base := range bases {
+		url := strings.TrimRight(base, "/") + "/domain/" + domain
+		result, err := c.fetchDomain(ctx, url, 0)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("RDAP lookup for %s failed: %w", domain, lastErr)
+}
+
+// fetchDomain fetches an RDAP domain object and follows at most one
+// "related" referral link, to reach a thin registry's authoritative data.
+func (c *Client) fetchDomain(ctx context.Context, url string, depth int) (*RDAPResult, error) {
+	if depth > 2 {
+		return nil, fmt.Errorf("too many RDAP referrals starting at %s", url)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 2*1024*1024))
+	if err != nil {
+		return nil, fmt.Errorf("read RDAP response from %s: %w", url, err)
+	}
+
+	var raw struct {
+		rdapDomain
+		Links []struct {
+			Rel  string `json:"rel"`
+			Href string `json:"href"`
+		} `json:"links"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("parse RDAP response from %s: %w", url, err)
+	}
+
+	result := &RDAPResult{URL: url, Status: raw.Status}
+	for _, ev := range raw.Events {
+		result.Events = append(result.Events, RDAPEvent{Action: ev.Action, Date: ev.Date})
+		if strings.EqualFold(ev.Action, "registration") {
+			result.CreatedAt = ev.Date
+		}
+	}
+	for _, ns := range raw.Nameservers {
+		if ns.LDHName != "" {
+			result.NameServers = append(result.NameServers, strings.ToLower(ns.LDHName))
+		}
+	}
+	for _, ent := range raw.Entities {
+		applyEntity(result, ent)
+	}
+
+	// Thin registries (classically verisign-grs for .com/.net) publish a
+	// "related" link pointing at the registrar's own RDAP server, which
+	// carries the registrant details the thin response omits.
+	for _, link := range raw.Links {
+		if strings.EqualFold(link.Rel, "related") && result.RegistrantOrg == "" {
+			if referred, err := c.fetchDomain(ctx, link.Href, depth+1); err == nil {
+				mergeRDAP(result, referred)
+			}
+			break
+		}
+	}
+
+	return result, nil
+}
+
+func applyEntity(result *RDAPResult, ent rdapEntity) {
+	for _, role := range ent.Roles {
+		switch strings.ToLower(role) {
+		case "registrar":
+			if org := ent.VCard.value("org"); org != "" {
+				result.Registrar = org
+			} else if fn := ent.VCard.value("fn"); fn != "" {
+				result.Registrar = fn
+			}
+			if country := ent.VCard.country("adr"); country != "" {
+				result.RegistrarCountry = country
+			}
+		case "registrant":
+			if org := ent.VCard.value("org"); org != "" {
+				result.RegistrantOrg = org
+			}
+			if adr := ent.VCard.value("adr"); adr != "" {
+				result.RegistrantAddr = adr
+			}
+		}
+	}
+	for _, nested := range ent.Entities {
+		applyEntity(result, nested)
+	}
+}
+
+func mergeRDAP(dst, src *RDAPResult) {
+	if dst.Registrar == "" {
+		dst.Registrar = src.Registrar
+	}
+	if dst.RegistrarCountry == "" {
+		dst.RegistrarCountry = src.RegistrarCountry
+	}
+	if dst.RegistrantOrg == "" {
+		dst.RegistrantOrg = src.RegistrantOrg
+	}
+	if dst.RegistrantAddr == "" {
+		dst.RegistrantAddr = src.RegistrantAddr
+	}
+	if dst.CreatedAt == "" {
+		dst.CreatedAt = src.CreatedAt
+	}
+}
+
+func tldOf(domain string) string {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	idx := strings.LastIndex(domain, ".")
+	if idx == -1 {
+		return domain
+	}
+	return domain[idx+1:]
+}
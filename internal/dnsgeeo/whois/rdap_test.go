@@ -0,0 +1,58 @@
+package whois
+
+import "testing"
+
+func TestRDAPVCardEntryValuePlainString(t *testing.T) {
+	var v rdapVCardEntry
+	if err := v.UnmarshalJSON([]byte(`["vcard",[["fn",{},"text","Example Registrar"]]]`)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if got := v.value("fn"); got != "Example Registrar" {
+		t.Fatalf("value(fn) = %q", got)
+	}
+}
+
+// TestRDAPVCardEntryValueStructured guards against a regression where a
+// structured jCard value (adr/org encoded as a JSON array per RFC 7095)
+// silently returned "" because of a bare f[3].(string) type assertion.
+func TestRDAPVCardEntryValueStructured(t *testing.T) {
+	var v rdapVCardEntry
+	raw := `["vcard",[["adr",{},"text",["","","123 Main St","Anytown","CA","99999","US"]]]]`
+	if err := v.UnmarshalJSON([]byte(raw)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	got := v.value("adr")
+	want := "123 Main St, Anytown, CA, 99999, US"
+	if got != want {
+		t.Fatalf("value(adr) = %q, want %q", got, want)
+	}
+}
+
+func TestRDAPVCardEntryCountry(t *testing.T) {
+	var v rdapVCardEntry
+	raw := `["vcard",[["adr",{},"text",["","","123 Main St","Anytown","CA","99999","US"]]]]`
+	if err := v.UnmarshalJSON([]byte(raw)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if got := v.country("adr"); got != "US" {
+		t.Fatalf("country(adr) = %q, want %q", got, "US")
+	}
+}
+
+func TestApplyEntityMergesRegistrarCountry(t *testing.T) {
+	result := &RDAPResult{}
+	var vcard rdapVCardEntry
+	raw := `["vcard",[["org",{},"text","Example Registrar"],["adr",{},"text",["","","","","","","FR"]]]]`
+	if err := vcard.UnmarshalJSON([]byte(raw)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+
+	applyEntity(result, rdapEntity{Roles: []string{"registrar"}, VCard: vcard})
+
+	if result.Registrar != "Example Registrar" {
+		t.Fatalf("Registrar = %q", result.Registrar)
+	}
+	if result.RegistrarCountry != "FR" {
+		t.Fatalf("RegistrarCountry = %q", result.RegistrarCountry)
+	}
+}
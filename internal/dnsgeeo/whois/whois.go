@@ -0,0 +1,111 @@
+package whois
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Info is the native equivalent of the old whois_rdap.py JSON record for a
+// single domain. Field names intentionally mirror dnsgeeo.WhoisToolInfo so
+// the caller can copy values across without any semantic translation.
+type Info struct {
+	Domain                     string
+	RootDomain                 string
+	Registrar                  string
+	RegistrarCountry           string
+	RegistrantOrg              string
+	RegistrantAddress          string
+	NameServers                []string
+	PSLRegistrableDomain       string
+	PSLPublicRegistrableDomain string
+	PSLPrivateSuffix           string
+	PSLPublicSuffix            string
+	PSLPrivateOwner            string
+	PSLIsPrivate               bool
+	RDAPURL                    string
+	RDAPCreatedAt              string
+	RDAPStatus                 []string
+	RDAPEvents                 []RDAPEvent
+	WhoisCreatedAt             string
+	WhoisUpdatedAt             string
+	WhoisExpiresAt             string
+	WhoisError                 string
+	RDAPError                  string
+}
+
+// Lookuper performs a combined RDAP + WHOIS/43 + PSL lookup for one domain.
+type Lookuper struct {
+	rdap    *Client
+	whois43 *Whois43Client
+	psl     *PSL
+}
+
+// NewLookuper wires together an RDAP client, a WHOIS/43 fallback client,
+// and an already-loaded PSL. Pass a nil psl to skip PSL enrichment.
+func NewLookuper(rdapCacheDir string, whoisTimeout time.Duration, psl *PSL) *Lookuper {
+	return &Lookuper{
+		rdap:    NewClient(rdapCacheDir),
+		whois43: NewWhois43Client(whoisTimeout),
+		psl:     psl,
+	}
+}
+
+// PSL returns the Lookuper's loaded Public Suffix List (nil if none was
+// supplied to NewLookuper).
+func (l *Lookuper) PSL() *PSL { return l.psl }
+
+// Lookup resolves Info for domain: RDAP first, falling back to WHOIS/43 when
+// RDAP has no bootstrap entry or the query fails, and always annotating the
+// result with PSL suffix/registrable-domain data when a PSL is loaded.
+func (l *Lookuper) Lookup(ctx context.Context, domain string) *Info {
+	info := &Info{Domain: domain}
+
+	if rdap, err := l.rdap.LookupDomain(ctx, domain); err == nil {
+		info.RDAPURL = rdap.URL
+		info.RDAPCreatedAt = rdap.CreatedAt
+		info.RDAPStatus = rdap.Status
+		info.RDAPEvents = rdap.Events
+		info.Registrar = rdap.Registrar
+		info.RegistrarCountry = rdap.RegistrarCountry
+		info.RegistrantOrg = rdap.RegistrantOrg
+		info.RegistrantAddress = rdap.RegistrantAddr
+		info.NameServers = rdap.NameServers
+	} else {
+		info.RDAPError = err.Error()
+	}
+
+	if info.Registrar == "" || len(info.NameServers) == 0 {
+		if w, err := l.whois43.Lookup(ctx, domain); err == nil {
+			if info.Registrar == "" {
+				info.Registrar = w.Registrar
+			}
+			if len(info.NameServers) == 0 {
+				info.NameServers = w.NameServers
+			}
+			if info.RDAPCreatedAt == "" {
+				info.WhoisCreatedAt = w.CreatedAt
+			}
+			info.WhoisUpdatedAt = w.UpdatedAt
+			info.WhoisExpiresAt = w.ExpiresAt
+		} else {
+			info.WhoisError = err.Error()
+		}
+	}
+
+	if l.psl != nil {
+		info.PSLPublicSuffix = l.psl.PublicSuffix(domain)
+		info.PSLPrivateSuffix = l.psl.PrivateSuffix(domain)
+		info.PSLPrivateOwner = l.psl.PrivateOwner(domain)
+		info.PSLIsPrivate = l.psl.IsPrivate(domain)
+		info.PSLPublicRegistrableDomain = l.psl.RegistrableDomain(domain)
+		info.PSLRegistrableDomain = l.psl.PrivateRegistrableDomain(domain)
+		info.RootDomain = info.PSLRegistrableDomain
+	}
+
+	if info.RDAPError != "" && info.WhoisError != "" {
+		info.WhoisError = fmt.Sprintf("rdap: %s; whois: %s", info.RDAPError, info.WhoisError)
+	}
+
+	return info
+}
@@ -0,0 +1,164 @@
+package whois
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const ianaWhoisServer = "whois.iana.org:43"
+
+// tldQuirks maps a TLD to the WHOIS server it should be queried against
+// directly, for registries whose IANA referral is missing, wrong, or whose
+// reply needs special-casing beyond the generic "refer:" convention.
+var tldQuirks = map[string]string{
+	"com": "whois.verisign-grs.com",
+	"net": "whois.verisign-grs.com",
+	"uk":  "whois.nic.uk",
+	"jp":  "whois.jprs.jp",
+	"de":  "whois.denic.de",
+	"ru":  "whois.tcinet.ru",
+}
+
+// Whois43Result is a coarse parse of a registry/registrar WHOIS/43 reply.
+type Whois43Result struct {
+	Server      string
+	Raw         string
+	Registrar   string
+	CreatedAt   string
+	UpdatedAt   string
+	ExpiresAt   string
+	NameServers []string
+}
+
+// Whois43Client performs classic port-43 WHOIS lookups, starting at IANA and
+// chasing "refer:"/"whois:" fields to the registry and then the registrar.
+type Whois43Client struct {
+	dialTimeout time.Duration
+}
+
+// NewWhois43Client returns a Whois43Client with the given per-connection
+// dial/read timeout (2s if timeout <= 0).
+func NewWhois43Client(timeout time.Duration) *Whois43Client {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Whois43Client{dialTimeout: timeout}
+}
+
+// Lookup queries WHOIS for domain, following referrals up to 4 hops deep.
+func (c *Whois43Client) Lookup(ctx context.Context, domain string) (*Whois43Result, error) {
+	server := ianaWhoisServer
+	if quirk, ok := tldQuirks[tldOf(domain)]; ok {
+		server = quirk + ":43"
+	}
+
+	var last *Whois43Result
+	seen := map[string]bool{}
+	for hop := 0; hop < 4; hop++ {
+		if seen[server] {
+			break
+		}
+		seen[server] = true
+
+		raw, err := c.query(ctx, server, domain)
+		if err != nil {
+			if last != nil {
+				return last, nil
+			}
+			return nil, err
+		}
+		result := parseWhois43(server, raw)
+		last = result
+
+		next := firstNonEmpty(extractField(raw, "refer"), extractField(raw, "whois"), extractField(raw, "Registrar WHOIS Server"))
+		if next == "" || strings.EqualFold(next, strings.TrimSuffix(server, ":43")) {
+			break
+		}
+		server = next + ":43"
+	}
+
+	if last == nil {
+		return nil, fmt.Errorf("no WHOIS data found for %s", domain)
+	}
+	return last, nil
+}
+
+func (c *Whois43Client) query(ctx context.Context, server, domain string) (string, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, c.dialTimeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(dialCtx, "tcp", server)
+	if err != nil {
+		return "", fmt.Errorf("dial %s: %w", server, err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := dialCtx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte(domain + "\r\n")); err != nil {
+		return "", fmt.Errorf("write query to %s: %w", server, err)
+	}
+
+	var sb strings.Builder
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		sb.WriteString(scanner.Text())
+		sb.WriteByte('\n')
+	}
+	return sb.String(), nil
+}
+
+func parseWhois43(server, raw string) *Whois43Result {
+	result := &Whois43Result{Server: server, Raw: raw}
+	result.Registrar = firstNonEmpty(extractField(raw, "Registrar"), extractField(raw, "registrar"))
+	result.CreatedAt = firstNonEmpty(extractField(raw, "Creation Date"), extractField(raw, "created"), extractField(raw, "Registered on"))
+	result.UpdatedAt = firstNonEmpty(extractField(raw, "Updated Date"), extractField(raw, "changed"))
+	result.ExpiresAt = firstNonEmpty(extractField(raw, "Registry Expiry Date"), extractField(raw, "expires"))
+
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+		if strings.HasPrefix(lower, "name server:") || strings.HasPrefix(lower, "nserver:") {
+			idx := strings.IndexRune(line, ':')
+			if idx != -1 {
+				ns := strings.ToLower(strings.TrimSpace(line[idx+1:]))
+				fields := strings.Fields(ns)
+				if len(fields) == 0 {
+					continue
+				}
+				result.NameServers = append(result.NameServers, fields[0])
+			}
+		}
+	}
+	return result
+}
+
+// extractField returns the value of the first "Key: value" line whose key
+// matches name case-insensitively.
+func extractField(raw, name string) string {
+	prefix := strings.ToLower(name) + ":"
+	for _, line := range strings.Split(raw, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(strings.ToLower(trimmed), prefix) {
+			return strings.TrimSpace(trimmed[len(prefix):])
+		}
+	}
+	return ""
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
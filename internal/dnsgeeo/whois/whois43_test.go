@@ -0,0 +1,48 @@
+package whois
+
+import "testing"
+
+func TestParseWhois43Fields(t *testing.T) {
+	raw := "Registrar: Example Registrar, Inc.\n" +
+		"Creation Date: 2010-01-02T15:04:05Z\n" +
+		"Updated Date: 2024-05-06T07:08:09Z\n" +
+		"Registry Expiry Date: 2030-01-02T15:04:05Z\n" +
+		"Name Server: NS1.EXAMPLE.COM\n" +
+		"Name Server: ns2.example.com\n"
+
+	result := parseWhois43("whois.example.com:43", raw)
+
+	if result.Registrar != "Example Registrar, Inc." {
+		t.Fatalf("Registrar = %q", result.Registrar)
+	}
+	if result.CreatedAt != "2010-01-02T15:04:05Z" {
+		t.Fatalf("CreatedAt = %q", result.CreatedAt)
+	}
+	if result.UpdatedAt != "2024-05-06T07:08:09Z" {
+		t.Fatalf("UpdatedAt = %q", result.UpdatedAt)
+	}
+	if result.ExpiresAt != "2030-01-02T15:04:05Z" {
+		t.Fatalf("ExpiresAt = %q", result.ExpiresAt)
+	}
+	want := []string{"ns1.example.com", "ns2.example.com"}
+	if len(result.NameServers) != len(want) {
+		t.Fatalf("NameServers = %v, want %v", result.NameServers, want)
+	}
+	for i, ns := range want {
+		if result.NameServers[i] != ns {
+			t.Fatalf("NameServers[%d] = %q, want %q", i, result.NameServers[i], ns)
+		}
+	}
+}
+
+// TestParseWhois43EmptyNameServerLine guards against a regression where a
+// malformed/empty "Name Server:" line panicked on strings.Fields(ns)[0].
+func TestParseWhois43EmptyNameServerLine(t *testing.T) {
+	raw := "Registrar: Example Registrar\nName Server: \nnserver:\n"
+
+	result := parseWhois43("whois.example.com:43", raw)
+
+	if len(result.NameServers) != 0 {
+		t.Fatalf("expected no name servers from blank lines, got %v", result.NameServers)
+	}
+}
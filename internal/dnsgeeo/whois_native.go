@@ -0,0 +1,199 @@
+package dnsgeeo
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"dnsgeeo/internal/dnsgeeo/whois"
+)
+
+// whoisSubsystem lazily builds the native RDAP/WHOIS/PSL stack used by
+// ResolveAndEnrichBatch. It replaces the old whois_rdap.py shell-out.
+var (
+	whoisOnce    sync.Once
+	whoisLook    *whois.Lookuper
+	whoisLoadErr error
+)
+
+// InitWhois eagerly loads the Public Suffix List and prepares the RDAP/
+// WHOIS clients, mirroring InitCache's eager-init convention. Safe to call
+// more than once; only the first call does any work.
+func InitWhois(ctx context.Context, cfg *Config) error {
+	whoisOnce.Do(func() {
+		cacheDir := cfg.WhoisCacheDir
+		if cacheDir == "" {
+			cacheDir = defaultWhoisCacheDir()
+		}
+		psl, err := whois.LoadPSL(ctx, filepath.Join(cacheDir, "public_suffix_list.dat"))
+		if err != nil {
+			// PSL enrichment is best-effort; fall back to RDAP/WHOIS-only.
+			psl = nil
+		}
+		whoisLook = whois.NewLookuper(filepath.Join(cacheDir, "rdap-bootstrap"), cfg.WhoisTimeout, psl)
+	})
+	return whoisLoadErr
+}
+
+func defaultWhoisCacheDir() string {
+	return filepath.Join(getenv("XDG_CACHE_HOME", filepath.Join(getenv("HOME", "."), ".cache")), "dnsgeeo")
+}
+
+// LookupWhoisBatch resolves native WHOIS/RDAP info for each domain and
+// converts it into the stable WhoisToolInfo JSON schema that ResolveAndEnrichBatch
+// has always emitted, so downstream consumers see no schema change.
+func LookupWhoisBatch(ctx context.Context, domains []string, cfg *Config) (map[string]*WhoisToolInfo, error) {
+	if err := InitWhois(ctx, cfg); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]*WhoisToolInfo, len(domains))
+	for _, domain := range domains {
+		info := whoisLook.Lookup(ctx, domain)
+		out[domain] = toWhoisToolInfo(info)
+	}
+	return out, nil
+}
+
+// LoadPSLPrivateList returns every PRIVATE-section Public Suffix List entry,
+// replacing the old `whois_rdap.py --psl-private-list` path.
+func LoadPSLPrivateList(ctx context.Context, cfg *Config) ([]PSLPrivateEntry, error) {
+	if err := InitWhois(ctx, cfg); err != nil {
+		return nil, err
+	}
+	if whoisLook == nil || whoisLook.PSL() == nil {
+		return nil, errors.New("public suffix list is not loaded")
+	}
+	entries := whoisLook.PSL().PrivateEntries()
+	out := make([]PSLPrivateEntry, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, PSLPrivateEntry{Suffix: e.Suffix, Owner: e.Owner})
+	}
+	return out, nil
+}
+
+func toWhoisToolInfo(info *whois.Info) *WhoisToolInfo {
+	events := make([]RDAPEvent, 0, len(info.RDAPEvents))
+	for _, ev := range info.RDAPEvents {
+		events = append(events, RDAPEvent{Action: ev.Action, Date: ev.Date})
+	}
+
+	createdAt := info.RDAPCreatedAt
+	createdAtSource := ""
+	switch {
+	case createdAt != "":
+		createdAtSource = "rdap"
+	case info.WhoisCreatedAt != "":
+		createdAt = info.WhoisCreatedAt
+		createdAtSource = "whois"
+	}
+
+	var ageDays *int
+	if t, ok := parseWhoisTime(createdAt); ok {
+		days := int(time.Since(t).Hours() / 24)
+		ageDays = &days
+	}
+
+	whoisExpiration := firstNonEmpty(rdapEventDate(info.RDAPEvents, "expiration"), info.WhoisExpiresAt)
+	whoisUpdated := firstNonEmpty(rdapEventDate(info.RDAPEvents, "last changed", "last update of rdap database"), info.WhoisUpdatedAt)
+
+	ddnsBySuffix := ddnsProviderForHost(info.Domain)
+	var ddnsByNS []string
+	seen := map[string]bool{}
+	for _, ns := range info.NameServers {
+		if provider := ddnsProviderForHost(ns); provider != "" && !seen[provider] {
+			seen[provider] = true
+			ddnsByNS = append(ddnsByNS, provider)
+		}
+	}
+	ddnsProviders := append([]string{}, ddnsByNS...)
+	if ddnsBySuffix != "" && !seen[ddnsBySuffix] {
+		ddnsProviders = append(ddnsProviders, ddnsBySuffix)
+	}
+	isAfraidHosted := ddnsBySuffix == ddnsProviderSuffixes["afraid.org"]
+	for _, provider := range ddnsByNS {
+		if provider == ddnsProviderSuffixes["afraid.org"] {
+			isAfraidHosted = true
+		}
+	}
+	if ddnsByNS == nil {
+		ddnsByNS = []string{}
+	}
+	if ddnsProviders == nil {
+		ddnsProviders = []string{}
+	}
+
+	return &WhoisToolInfo{
+		Domain:                     info.Domain,
+		RootDomain:                 info.RootDomain,
+		Registrar:                  info.Registrar,
+		RegistrarCountry:           info.RegistrarCountry,
+		RegistrantOrg:              info.RegistrantOrg,
+		RegistrantAddress:          info.RegistrantAddress,
+		NameServers:                info.NameServers,
+		IsAfraidHosted:             isAfraidHosted,
+		PSLRegistrableDomain:       info.PSLRegistrableDomain,
+		PSLPublicRegistrableDomain: info.PSLPublicRegistrableDomain,
+		PSLPrivateSuffix:           info.PSLPrivateSuffix,
+		PSLPublicSuffix:            info.PSLPublicSuffix,
+		PSLPrivateOwner:            info.PSLPrivateOwner,
+		PSLIsPrivate:               info.PSLIsPrivate,
+		DDNSProviderBySuffix:       ddnsBySuffix,
+		DDNSProvidersByNS:          ddnsByNS,
+		DDNSProviders:              ddnsProviders,
+		CreatedAt:                  createdAt,
+		CreatedAtSource:            createdAtSource,
+		AgeDays:                    ageDays,
+		RDAPURL:                    info.RDAPURL,
+		RDAPCreatedAt:              info.RDAPCreatedAt,
+		RDAPStatus:                 info.RDAPStatus,
+		RDAPEvents:                 events,
+		WhoisCreatedAt:             info.WhoisCreatedAt,
+		WhoisExpirationDate:        whoisExpiration,
+		WhoisUpdatedDate:           whoisUpdated,
+		WhoisError:                 info.WhoisError,
+		RDAPError:                  info.RDAPError,
+	}
+}
+
+// rdapEventDate returns the date of the first RDAP event whose action
+// case-insensitively matches any of wantActions, or "" if none do.
+func rdapEventDate(events []whois.RDAPEvent, wantActions ...string) string {
+	for _, ev := range events {
+		for _, want := range wantActions {
+			if strings.EqualFold(ev.Action, want) {
+				return ev.Date
+			}
+		}
+	}
+	return ""
+}
+
+// parseWhoisTime parses the handful of timestamp formats RDAP and WHOIS/43
+// servers actually use in practice (RFC3339 is the RDAP norm; WHOIS/43
+// replies are inconsistent enough to warrant date-only and Z-suffixed
+// fallbacks).
+func parseWhoisTime(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	formats := []string{time.RFC3339, "2006-01-02T15:04:05Z", "2006-01-02T15:04:05", "2006-01-02"}
+	for _, f := range formats {
+		if t, err := time.Parse(f, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package dnsgeeo
+
+import (
+	"testing"
+
+	"dnsgeeo/internal/dnsgeeo/whois"
+)
+
+func TestToWhoisToolInfoPrefersRDAPOverWhois(t *testing.T) {
+	info := &whois.Info{
+		Domain:         "example.com",
+		RDAPCreatedAt:  "2010-01-02T15:04:05Z",
+		WhoisCreatedAt: "2009-01-01T00:00:00Z",
+		RDAPEvents: []whois.RDAPEvent{
+			{Action: "registration", Date: "2010-01-02T15:04:05Z"},
+			{Action: "expiration", Date: "2030-01-02T15:04:05Z"},
+		},
+		NameServers: []string{"ns1.no-ip.org", "ns2.example.com"},
+	}
+
+	out := toWhoisToolInfo(info)
+
+	if out.CreatedAt != "2010-01-02T15:04:05Z" || out.CreatedAtSource != "rdap" {
+		t.Fatalf("CreatedAt = %q (%q), want RDAP value", out.CreatedAt, out.CreatedAtSource)
+	}
+	if out.AgeDays == nil || *out.AgeDays <= 0 {
+		t.Fatalf("AgeDays = %v, want a positive age", out.AgeDays)
+	}
+	if out.WhoisExpirationDate != "2030-01-02T15:04:05Z" {
+		t.Fatalf("WhoisExpirationDate = %q", out.WhoisExpirationDate)
+	}
+	if len(out.DDNSProvidersByNS) != 1 || out.DDNSProvidersByNS[0] != "No-IP" {
+		t.Fatalf("DDNSProvidersByNS = %v, want [No-IP]", out.DDNSProvidersByNS)
+	}
+}
+
+func TestToWhoisToolInfoFallsBackToWhois43(t *testing.T) {
+	info := &whois.Info{
+		Domain:         "example.org",
+		WhoisCreatedAt: "2009-01-01T00:00:00Z",
+		WhoisExpiresAt: "2031-01-01T00:00:00Z",
+		WhoisUpdatedAt: "2020-06-01T00:00:00Z",
+	}
+
+	out := toWhoisToolInfo(info)
+
+	if out.CreatedAt != "2009-01-01T00:00:00Z" || out.CreatedAtSource != "whois" {
+		t.Fatalf("CreatedAt = %q (%q), want WHOIS/43 value", out.CreatedAt, out.CreatedAtSource)
+	}
+	if out.WhoisExpirationDate != "2031-01-01T00:00:00Z" {
+		t.Fatalf("WhoisExpirationDate = %q", out.WhoisExpirationDate)
+	}
+	if out.WhoisUpdatedDate != "2020-06-01T00:00:00Z" {
+		t.Fatalf("WhoisUpdatedDate = %q", out.WhoisUpdatedDate)
+	}
+}
@@ -0,0 +1,72 @@
+package dnsgeeo
+
+import (
+	"net"
+	"strings"
+)
+
+// WhoisToolInfo is the stable WHOIS/RDAP/PSL JSON schema attached to
+// HostResult.Whois. It is populated by toWhoisToolInfo in whois_native.go
+// from the native internal/dnsgeeo/whois subsystem.
+type WhoisToolInfo struct {
+	Domain                     string      `json:"domain"`
+	RootDomain                 string      `json:"root_domain,omitempty"`
+	Registrar                  string      `json:"registrar,omitempty"`
+	RegistrarCountry           string      `json:"registrar_country,omitempty"`
+	RegistrantOrg              string      `json:"registrant_org,omitempty"`
+	RegistrantAddress          string      `json:"registrant_address,omitempty"`
+	NameServers                []string    `json:"name_servers,omitempty"`
+	IsAfraidHosted             bool        `json:"is_afraid_hosted"`
+	PSLRegistrableDomain       string      `json:"psl_registrable_domain,omitempty"`
+	PSLPublicRegistrableDomain string      `json:"psl_public_registrable_domain,omitempty"`
+	PSLPrivateSuffix           string      `json:"psl_private_suffix,omitempty"`
+	PSLPublicSuffix            string      `json:"psl_public_suffix,omitempty"`
+	PSLPrivateOwner            string      `json:"psl_private_owner,omitempty"`
+	PSLIsPrivate               bool        `json:"psl_is_private"`
+	DDNSProviderBySuffix       string      `json:"ddns_provider_by_suffix"`
+	DDNSProvidersByNS          []string    `json:"ddns_providers_by_ns"`
+	DDNSProviders              []string    `json:"ddns_providers"`
+	CreatedAt                  string      `json:"created_at,omitempty"`
+	CreatedAtSource            string      `json:"created_at_source,omitempty"`
+	AgeDays                    *int        `json:"age_days,omitempty"`
+	RDAPURL                    string      `json:"rdap_url,omitempty"`
+	RDAPCreatedAt              string      `json:"rdap_created_at,omitempty"`
+	RDAPStatus                 []string    `json:"rdap_status,omitempty"`
+	RDAPEvents                 []RDAPEvent `json:"rdap_events,omitempty"`
+	WhoisCreatedAt             string      `json:"whois_created_at,omitempty"`
+	WhoisExpirationDate        string      `json:"whois_expiration_date,omitempty"`
+	WhoisUpdatedDate           string      `json:"whois_updated_date,omitempty"`
+	WhoisError                 string      `json:"whois_error,omitempty"`
+	RDAPError                  string      `json:"rdap_error,omitempty"`
+	CacheHit                   bool        `json:"cache_hit,omitempty"`
+}
+
+type RDAPEvent struct {
+	Action string `json:"action,omitempty"`
+	Date   string `json:"date,omitempty"`
+}
+
+type PSLPrivateEntry struct {
+	Suffix string `json:"suffix"`
+	Owner  string `json:"owner,omitempty"`
+}
+
+func uniqueDomains(inputs []string) []string {
+	seen := make(map[string]struct{}, len(inputs))
+	var out []string
+	for _, raw := range inputs {
+		host := strings.TrimSpace(strings.TrimSuffix(raw, "."))
+		if host == "" {
+			continue
+		}
+		if net.ParseIP(host) != nil {
+			continue
+		}
+		if _, ok := seen[host]; ok {
+			continue
+		}
+		seen[host] = struct{}{}
+		out = append(out, host)
+	}
+	return out
+}